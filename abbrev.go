@@ -0,0 +1,79 @@
+package goptions
+
+import (
+	"sort"
+	"strings"
+)
+
+// exactFlag returns the flag with exactly the given name or nil.
+func (fs *FlagSet) exactFlag(arg string) *Flag {
+	if isShort(arg) {
+		return fs.shortMap[arg[1:2]]
+	}
+	if isLong(arg) {
+		name, _, _ := splitLong(arg)
+		return fs.longMap[name]
+	}
+	return nil
+}
+
+// longFlagCandidates returns the keys in longMap name can refer to. This is
+// name itself if it is a key. Otherwise, if the top-level FlagSet allows
+// Abbreviations, all keys name is a prefix of are returned. persistent
+// restricts the candidates to persistent flags.
+func (fs *FlagSet) longFlagCandidates(name string, persistent bool) []string {
+	if f, ok := fs.longMap[name]; ok && (!persistent || f.Persistent) {
+		return []string{name}
+	}
+	r := []string{}
+	if len(name) == 0 || !fs.root().Abbreviations {
+		return r
+	}
+	for key, f := range fs.longMap {
+		if strings.HasPrefix(key, name) && (!persistent || f.Persistent) {
+			r = append(r, key)
+		}
+	}
+	sort.Strings(r)
+	return r
+}
+
+// resolveVerb returns the verb with the given name or alias. If the
+// top-level FlagSet allows Abbreviations, name can be a prefix of the name
+// or an alias of exactly one visible verb. nil is returned if no verb
+// matches.
+func (fs *FlagSet) resolveVerb(name string) (*FlagSet, error) {
+	if canonical, ok := fs.verbAliases[name]; ok {
+		name = canonical
+	}
+	if verb, ok := fs.Verbs[name]; ok {
+		return verb, nil
+	}
+	if len(name) == 0 || !fs.root().Abbreviations {
+		return nil, nil
+	}
+	matches := []*FlagSet{}
+	for _, verb := range fs.Verbs {
+		if verb.Hidden {
+			continue
+		}
+		for _, n := range append([]string{verb.Name}, verb.Aliases...) {
+			if strings.HasPrefix(n, name) {
+				matches = append(matches, verb)
+				break
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, nil
+	case 1:
+		return matches[0], nil
+	}
+	candidates := make([]string, 0, len(matches))
+	for _, verb := range matches {
+		candidates = append(candidates, verb.Name)
+	}
+	sort.Strings(candidates)
+	return nil, &AmbiguousError{ErrorContext{Verbs: fs.verbPath(), Index: -1}, name, candidates}
+}
@@ -0,0 +1,92 @@
+package goptions
+
+import (
+	"errors"
+	"testing"
+)
+
+type abbrevOptions struct {
+	Verbose bool   `goptions:"-v, --verbose"`
+	Version bool   `goptions:"--version"`
+	Name    string `goptions:"-n, --name, persistent"`
+	Color   bool   `goptions:"--color, negatable"`
+	Col     int    `goptions:"--col"`
+
+	Verbs
+	Create struct {
+		Names []string `goptions:"--names"`
+	} `goptions:"create"`
+	Copy struct {
+		Force bool `goptions:"-f, --force"`
+	} `goptions:"copy, alias='duplicate'"`
+	Debug struct{} `goptions:"debug, hidden"`
+}
+
+func TestParse_Abbreviations(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options abbrevOptions
+
+	args = []string{"--verb", "--na=SomeName", "--no-c", "--col=3", "dup", "--fo"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Abbreviations = true
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbose &&
+		!options.Version &&
+		options.Name == "SomeName" &&
+		!options.Color &&
+		options.Col == 3 &&
+		options.Verbs == "copy" &&
+		options.Copy.Force) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	// Exact matches take priority, local flags over persistent ones.
+	args = []string{"cr", "--name", "x"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Abbreviations = true
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbs == "create" && options.Name == "x" && len(options.Create.Names) == 0) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse([]string{"--verb"})
+	if err == nil {
+		t.Fatalf("Parsing should have failed without Abbreviations")
+	}
+}
+
+func TestParse_AmbiguousAbbreviations(t *testing.T) {
+	var options abbrevOptions
+
+	for expected, args := range map[string][]string{
+		"Ambiguous flag --ver, could be --verbose, --version": {"--ver"},
+		"Ambiguous flag --co, could be --col, --color":        {"--co=1"},
+		"Ambiguous verb c, could be copy, create":             {"c"},
+		"Ambiguous flag --n, could be --name, --names":        {"create", "--n"},
+	} {
+		fs := NewFlagSet("goptions", &options)
+		fs.Abbreviations = true
+		err := fs.Parse(args)
+		var ambiguous *AmbiguousError
+		if !errors.As(err, &ambiguous) || err.Error() != expected {
+			t.Fatalf("Unexpected error for %v: %v", args, err)
+		}
+	}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.Abbreviations = true
+	err := fs.Parse([]string{"deb"})
+	var unknown *UnknownVerbError
+	if !errors.As(err, &unknown) {
+		t.Fatalf("Hidden verbs should not be abbreviated: %v", err)
+	}
+}
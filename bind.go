@@ -0,0 +1,107 @@
+package goptions
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Reset restores the default values of all flags of the FlagSet and its
+// verbs and forgets which flags and verbs have been specified. Afterwards,
+// the FlagSet can be parsed again.
+func (fs *FlagSet) Reset() {
+	fs.tokens, fs.errs = nil, nil
+	fs.walk(func(set *FlagSet) {
+		set.selected = nil
+		for _, f := range set.allFlags() {
+			f.reset()
+		}
+	})
+}
+
+// Bind returns a copy of the FlagSet which stores the values in v instead of
+// the struct the FlagSet has been created with. v has to be a pointer to a
+// struct of the same type. The settings of the FlagSet and its verbs (e.g.
+// Mode or EnvPrefix) are copied, the results of previous calls to Parse()
+// are not. The default values are taken from v.
+// Bind panics if v is of the wrong type.
+func (fs *FlagSet) Bind(v interface{}) *FlagSet {
+	structValue := reflect.ValueOf(v)
+	if structValue.Kind() != reflect.Ptr || structValue.Elem().Type() != fs.structValue.Type() {
+		panic(fmt.Sprintf("Value type is not a pointer to %s", fs.structValue.Type()))
+	}
+	return fs.bind(structValue.Elem(), fs.parent, make(map[*Flag]*Flag))
+}
+
+func (fs *FlagSet) bind(structValue reflect.Value, parent *FlagSet, flags map[*Flag]*Flag) *FlagSet {
+	r := new(FlagSet)
+	*r = *fs
+	r.parent = parent
+	r.selected = nil
+	r.tokens, r.errs = nil, nil
+	r.structValue = structValue
+
+	// Flags inherited from the parent (like the remainder) have been bound
+	// already.
+	var bindFlag func(f *Flag) *Flag
+	bindFlag = func(f *Flag) *Flag {
+		if f == nil {
+			return nil
+		}
+		if bound, ok := flags[f]; ok {
+			return bound
+		}
+		bound := new(Flag)
+		*bound = *f
+		bound.flagSet = r
+		bound.value = structValue.Field(f.index)
+		if f.DefaultValue != nil {
+			bound.DefaultValue = copyValue(bound.value).Interface()
+		}
+		bound.WasSpecified = false
+		bound.Source = Source{}
+		flags[f] = bound
+		bound.counter = bindFlag(f.counter)
+		return bound
+	}
+	r.Flags = make([]*Flag, 0, len(fs.Flags))
+	for _, f := range fs.Flags {
+		r.Flags = append(r.Flags, bindFlag(f))
+	}
+	r.Positionals = nil
+	for _, f := range fs.Positionals {
+		r.Positionals = append(r.Positionals, bindFlag(f))
+	}
+	r.helpFlag = bindFlag(fs.helpFlag)
+	r.remainderFlag = bindFlag(fs.remainderFlag)
+	r.verbFlag = bindFlag(fs.verbFlag)
+	if fs.Verbs != nil {
+		r.Verbs = make(map[string]*FlagSet)
+		for name, verb := range fs.Verbs {
+			r.Verbs[name] = verb.bind(structValue.Field(verb.index), r, flags)
+		}
+	}
+	r.createMaps()
+	return r
+}
+
+// allFlags returns all flags of the FlagSet including positional arguments,
+// the remainder and the verb.
+func (fs *FlagSet) allFlags() []*Flag {
+	r := append([]*Flag{}, fs.Flags...)
+	r = append(r, fs.Positionals...)
+	for _, f := range []*Flag{fs.remainderFlag, fs.verbFlag} {
+		if f != nil {
+			r = append(r, f)
+		}
+	}
+	return r
+}
+
+// reset restores the default value of the flag.
+func (f *Flag) reset() {
+	if f.DefaultValue != nil {
+		f.value.Set(copyValue(reflect.ValueOf(f.DefaultValue)))
+	}
+	f.WasSpecified = false
+	f.Source = Source{}
+}
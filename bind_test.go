@@ -0,0 +1,95 @@
+package goptions
+
+import (
+	"reflect"
+	"testing"
+)
+
+type bindOptions struct {
+	Name    string   `goptions:"-n, --name, obligatory"`
+	Servers []string `goptions:"-s, --server"`
+	Verbose int      `goptions:"-v, count"`
+	Remainder
+
+	Verbs
+	Delete struct {
+		Force bool   `goptions:"-f, --force"`
+		Path  string `goptions:"pos=1"`
+	} `goptions:"delete"`
+}
+
+func TestFlagSet_Reset(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	options := bindOptions{
+		Servers: []string{"localhost"},
+	}
+
+	args = []string{"-n", "SomeName", "-s", "a", "-vv", "delete", "-f", "/tmp", "rest"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+
+	fs.Reset()
+	if !(options.Name == "" &&
+		reflect.DeepEqual(options.Servers, []string{"localhost"}) &&
+		options.Verbose == 0 &&
+		len(options.Remainder) == 0 &&
+		options.Verbs == "" &&
+		!options.Delete.Force &&
+		options.Delete.Path == "" &&
+		len(fs.SelectedVerbs()) == 0) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"-n", "OtherName", "-v", "delete", "/var"}
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "OtherName" &&
+		reflect.DeepEqual(options.Servers, []string{"localhost"}) &&
+		options.Verbose == 1 &&
+		options.Verbs == "delete" &&
+		!options.Delete.Force &&
+		options.Delete.Path == "/var") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestFlagSet_Bind(t *testing.T) {
+	var args []string
+	var err error
+	var fs, bound *FlagSet
+	var options, other bindOptions
+
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	bound = fs.Bind(&other)
+
+	args = []string{"-n", "SomeName", "delete", "/tmp", "-f", "rest"}
+	err = bound.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(other.Name == "SomeName" &&
+		other.Verbs == "delete" &&
+		other.Delete.Force &&
+		other.Delete.Path == "/tmp" &&
+		reflect.DeepEqual(other.Remainder, Remainder{"rest"})) {
+		t.Fatalf("Unexpected value: %#v", other)
+	}
+	if !reflect.DeepEqual(options, bindOptions{}) || fs.Flags[0].WasSpecified {
+		t.Fatalf("Original FlagSet changed: %#v", options)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatalf("Binding a different type should panic")
+		}
+	}()
+	fs.Bind(&struct{}{})
+}
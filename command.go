@@ -0,0 +1,120 @@
+package goptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+)
+
+// A Command is an options struct which can be run by FlagSet.Execute(). If
+// a verb's struct implements Command, it is run when the verb has been
+// selected. global is a pointer to the top-level options struct.
+type Command interface {
+	Run(ctx context.Context, global interface{}) error
+}
+
+// A BeforeHook is an options struct whose Before() is called prior to the
+// selected Command. Hooks of outer levels are called first.
+type BeforeHook interface {
+	Before(ctx context.Context, global interface{}) error
+}
+
+// An AfterHook is an options struct whose After() is called once the
+// selected Command has finished. Hooks of inner levels are called first.
+// After() is called even if the Command failed, as long as the
+// corresponding Before() (if any) succeeded.
+type AfterHook interface {
+	After(ctx context.Context, global interface{}) error
+}
+
+// An ExitCoder is an error carrying the exit code the program should
+// terminate with.
+type ExitCoder interface {
+	ExitCode() int
+}
+
+var (
+	ErrNoCommand = errors.New("No command to run")
+)
+
+// ExitCode maps an error returned by FlagSet.Execute() to an exit code. nil
+// and ErrHelpRequest map to 0, errors implementing ExitCoder to their exit
+// code and all other errors to 1.
+func ExitCode(err error) int {
+	if err == nil || err == ErrHelpRequest {
+		return 0
+	}
+	var ec ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return 1
+}
+
+// Execute parses the command line arguments and runs the selected Command.
+func (fs *FlagSet) Execute(ctx context.Context, args []string) error {
+	err := fs.Parse(args)
+	if err != nil {
+		return err
+	}
+	return fs.Run(ctx)
+}
+
+// Run runs the Command selected by the last call to Parse(). The struct of
+// the innermost selected verb (or the top-level struct if no verb has been
+// selected) has to implement Command, otherwise ErrNoCommand is returned.
+// BeforeHooks and AfterHooks of all selected levels are called around it.
+func (fs *FlagSet) Run(ctx context.Context) error {
+	path := fs.selectedPath()
+	cmd, ok := path[len(path)-1].options().(Command)
+	if !ok {
+		return ErrNoCommand
+	}
+	global := fs.root().options()
+
+	var err error
+	entered := 0
+	for _, set := range path {
+		if hook, ok := set.options().(BeforeHook); ok {
+			err = hook.Before(ctx, global)
+			if err != nil {
+				break
+			}
+		}
+		entered++
+	}
+	if err == nil {
+		err = cmd.Run(ctx, global)
+	}
+	for i := entered - 1; i >= 0; i-- {
+		if hook, ok := path[i].options().(AfterHook); ok {
+			afterErr := hook.After(ctx, global)
+			if err == nil {
+				err = afterErr
+			}
+		}
+	}
+	return err
+}
+
+// ParseAndRun parses the command line arguments like ParseAndFail() and runs
+// the selected Command afterwards. If the Command fails, the error is printed
+// and the program exits with the code determined by ExitCode().
+func (fs *FlagSet) ParseAndRun(w io.Writer, args []string) {
+	fs.ParseAndFail(w, args)
+	err := fs.Run(context.Background())
+	if err != nil {
+		fmt.Fprintf(w, "Error: %s\n", err)
+		if err == ErrNoCommand {
+			fs.PrintHelp(w)
+		}
+	}
+	os.Exit(ExitCode(err))
+}
+
+// options returns a pointer to the FlagSet's struct.
+func (fs *FlagSet) options() interface{} {
+	return fs.structValue.Addr().Interface()
+}
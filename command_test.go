@@ -0,0 +1,134 @@
+package goptions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+type exitError int
+
+func (e exitError) Error() string {
+	return fmt.Sprintf("Exit with %d", int(e))
+}
+
+func (e exitError) ExitCode() int {
+	return int(e)
+}
+
+type commandDelete struct {
+	Name string `goptions:"-n, --name"`
+	Fail bool   `goptions:"--fail"`
+}
+
+func (c *commandDelete) Before(ctx context.Context, global interface{}) error {
+	global.(*commandOptions).calls = append(global.(*commandOptions).calls, "before delete")
+	return nil
+}
+
+func (c *commandDelete) Run(ctx context.Context, global interface{}) error {
+	g := global.(*commandOptions)
+	g.calls = append(g.calls, "run delete "+c.Name)
+	if c.Fail {
+		return exitError(3)
+	}
+	return nil
+}
+
+func (c *commandDelete) After(ctx context.Context, global interface{}) error {
+	global.(*commandOptions).calls = append(global.(*commandOptions).calls, "after delete")
+	return nil
+}
+
+type commandOptions struct {
+	FailBefore bool `goptions:"--fail-before"`
+	calls      []string
+
+	Verbs
+	Delete commandDelete `goptions:"delete"`
+	List   struct{}      `goptions:"list"`
+}
+
+func (o *commandOptions) Before(ctx context.Context, global interface{}) error {
+	o.calls = append(o.calls, "before global")
+	if o.FailBefore {
+		return errors.New("Before failed")
+	}
+	return nil
+}
+
+func (o *commandOptions) After(ctx context.Context, global interface{}) error {
+	o.calls = append(o.calls, "after global")
+	return nil
+}
+
+func TestExecute(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options commandOptions
+
+	args = []string{"delete", "-n", "SomeName"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatalf("Execution failed: %s", err)
+	}
+	expected := []string{"before global", "before delete", "run delete SomeName", "after delete", "after global"}
+	if !reflect.DeepEqual(options.calls, expected) {
+		t.Fatalf("Unexpected calls: %#v", options.calls)
+	}
+
+	options = commandOptions{}
+	args = []string{"delete", "--fail"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Execute(context.Background(), args)
+	if ExitCode(err) != 3 {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(options.calls) != 5 {
+		t.Fatalf("Unexpected calls: %#v", options.calls)
+	}
+
+	options = commandOptions{}
+	args = []string{"--fail-before", "delete"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Execute(context.Background(), args)
+	if err == nil || ExitCode(err) != 1 {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !reflect.DeepEqual(options.calls, []string{"before global"}) {
+		t.Fatalf("Unexpected calls: %#v", options.calls)
+	}
+}
+
+func TestExecute_NoCommand(t *testing.T) {
+	var err error
+	var fs *FlagSet
+	var options commandOptions
+
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Execute(context.Background(), []string{"list"})
+	if err != ErrNoCommand {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(options.calls) != 0 {
+		t.Fatalf("Unexpected calls: %#v", options.calls)
+	}
+}
+
+func TestExitCode(t *testing.T) {
+	for err, expected := range map[error]int{
+		nil:                            0,
+		ErrHelpRequest:                 0,
+		errors.New("Some error"):       1,
+		exitError(42):                  42,
+		fmt.Errorf("%w", exitError(4)): 4,
+	} {
+		if code := ExitCode(err); code != expected {
+			t.Fatalf("Unexpected exit code for %v: %d", err, code)
+		}
+	}
+}
@@ -0,0 +1,368 @@
+package goptions
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// A configSection holds the values of one section of a configuration file.
+// Nested sections correspond to verbs.
+type configSection struct {
+	values   []*configValue
+	sections map[string]*configSection
+}
+
+// A configValue holds all values of a key in a configuration file together
+// with the line of each value.
+type configValue struct {
+	key    string
+	values []string
+	lines  []int
+}
+
+func newConfigSection() *configSection {
+	return &configSection{
+		values:   make([]*configValue, 0),
+		sections: make(map[string]*configSection),
+	}
+}
+
+// section returns the (possibly nested) subsection with the given path and
+// creates it if necessary.
+func (cs *configSection) section(path ...string) *configSection {
+	r := cs
+	for _, name := range path {
+		sub, ok := r.sections[name]
+		if !ok {
+			sub = newConfigSection()
+			r.sections[name] = sub
+		}
+		r = sub
+	}
+	return r
+}
+
+// add appends a value for key to the section. Values of repeated keys are
+// collected.
+func (cs *configSection) add(key, value string, line int) {
+	for _, cv := range cs.values {
+		if cv.key == key {
+			cv.values = append(cv.values, value)
+			cv.lines = append(cv.lines, line)
+			return
+		}
+	}
+	cs.values = append(cs.values, &configValue{
+		key:    key,
+		values: []string{value},
+		lines:  []int{line},
+	})
+}
+
+// configFile returns the path of the configuration file to load. A
+// ConfigFile flag takes precedence over the FlagSet's ConfigPath. required
+// is true if the path has been specified explicitly.
+func (fs *FlagSet) configFile() (path string, required bool) {
+	for _, set := range fs.selectedPath() {
+		for _, f := range set.Flags {
+			if v, ok := f.value.Interface().(ConfigFile); ok && len(v) > 0 {
+				return string(v), f.WasSpecified
+			}
+		}
+	}
+	return fs.ConfigPath, false
+}
+
+// loadConfig reads the configuration file and sets all flags which have not
+// been specified on the command line or in the environment.
+func (fs *FlagSet) loadConfig() error {
+	path, required := fs.configFile()
+	if len(path) == 0 {
+		return nil
+	}
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) && !required {
+		return nil
+	} else if err != nil {
+		return fmt.Errorf("Could not read config file: %s", err)
+	}
+
+	var cs *configSection
+	switch ext := filepath.Ext(path); {
+	case ext == ".json":
+		cs, err = parseJSONConfig(data)
+	case ext == ".env" || filepath.Base(path) == ".env":
+		cs, err = fs.parseDotenvConfig(data)
+	default:
+		cs, err = parseINIConfig(data)
+	}
+	if err != nil {
+		return fmt.Errorf("%s:%s", path, err)
+	}
+	return fs.applyConfig(cs, path)
+}
+
+// applyConfig sets all flags of the FlagSet and its selected verbs which
+// have not been specified yet from the configuration section. Sections of
+// verbs which have not been selected are ignored.
+func (fs *FlagSet) applyConfig(cs *configSection, path string) error {
+	shadowed := fs.shadowedFlags()
+	for _, cv := range cs.values {
+		f, ok := fs.longMap[cv.key]
+		if !ok || len(cv.key) == 0 || !f.acceptsEnv() {
+			return fmt.Errorf("%s:%d: Unknown key %s", path, cv.lines[0], cv.key)
+		}
+		if _, ok := f.value.Interface().(ConfigFile); ok || f.WasSpecified || shadowed[f] {
+			continue
+		}
+		if len(cv.values) > 1 && !f.IsMulti() {
+			return fmt.Errorf("%s:%d: Flag %s can only be specified once", path, cv.lines[1], f.Name())
+		}
+		f.resetDefault()
+		for i, value := range cv.values {
+			if f.isNegation(cv.key) {
+				value = strconv.FormatBool(!isTrue(value))
+			}
+			err := f.setExternalValue(value, "")
+			if err != nil {
+				err = fs.addContext(f.conversionError(value, err), -1)
+				err = fs.recoverError(fmt.Errorf("%s:%d: %s: %w", path, cv.lines[i], f.Name(), err))
+				if err != nil {
+					return err
+				}
+			}
+		}
+		f.WasSpecified = true
+		f.Source = Source{Kind: SourceConfig, Name: path, Line: cv.lines[0]}
+	}
+	for name, sub := range cs.sections {
+		if f, ok := fs.longMap[name]; ok && f.IsMap() {
+			err := f.applyConfigMap(sub, path, shadowed[f])
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		verb, ok := fs.VerbByName(name)
+		if !ok {
+			return fmt.Errorf("%s: Unknown section %s", path, name)
+		}
+		if verb != fs.selected {
+			continue
+		}
+		err := verb.applyConfig(sub, path)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyConfigMap adds every key of the section as a pair to the map flag
+// unless it has been specified already.
+func (f *Flag) applyConfigMap(cs *configSection, path string, shadowed bool) error {
+	if len(cs.sections) > 0 {
+		return fmt.Errorf("%s: Nested section in %s", path, f.Name())
+	}
+	if f.WasSpecified || shadowed || len(cs.values) == 0 {
+		return nil
+	}
+	f.resetDefault()
+	for _, cv := range cs.values {
+		for i, value := range cv.values {
+			err := f.setMapIndex(cv.key, value)
+			if err != nil {
+				err = f.flagSet.addContext(f.conversionError(value, err), -1)
+				err = f.flagSet.recoverError(fmt.Errorf("%s:%d: %s: %w", path, cv.lines[i], f.Name(), err))
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+	f.WasSpecified = true
+	f.Source = Source{Kind: SourceConfig, Name: path, Line: cs.values[0].lines[0]}
+	return nil
+}
+
+func isTrue(s string) bool {
+	v, err := boolValueParser(nil, s)
+	return err == nil && v.Bool()
+}
+
+// parseJSONConfig parses a JSON configuration file. The top-level value has
+// to be an object. Nested objects are sections, arrays hold multiple values
+// for one key.
+func parseJSONConfig(data []byte) (*configSection, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	line := func() int {
+		return bytes.Count(data[:int(dec.InputOffset())], []byte("\n")) + 1
+	}
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, fmt.Errorf("%d: %s", line(), err)
+	}
+	if tok != json.Delim('{') {
+		return nil, fmt.Errorf("%d: Configuration has to be a JSON object", line())
+	}
+	cs := newConfigSection()
+	err = parseJSONObject(dec, cs, line)
+	if err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+func parseJSONObject(dec *json.Decoder, cs *configSection, line func() int) error {
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("%d: %s", line(), err)
+		}
+		key, l := tok.(string), line()
+		tok, err = dec.Token()
+		if err != nil {
+			return fmt.Errorf("%d: %s", line(), err)
+		}
+		switch tok {
+		case json.Delim('{'):
+			err = parseJSONObject(dec, cs.section(key), line)
+			if err != nil {
+				return err
+			}
+		case json.Delim('['):
+			for dec.More() {
+				tok, err = dec.Token()
+				if err != nil {
+					return fmt.Errorf("%d: %s", line(), err)
+				}
+				value, ok := jsonScalar(tok)
+				if !ok {
+					return fmt.Errorf("%d: Invalid value in array %s", line(), key)
+				}
+				cs.add(key, value, l)
+			}
+			// Closing bracket
+			dec.Token()
+		default:
+			if tok == nil {
+				continue
+			}
+			value, _ := jsonScalar(tok)
+			cs.add(key, value, l)
+		}
+	}
+	// Closing brace
+	_, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("%d: %s", line(), err)
+	}
+	return nil
+}
+
+// jsonScalar converts a JSON string, number or boolean token to a string.
+func jsonScalar(tok interface{}) (string, bool) {
+	switch v := tok.(type) {
+	case string:
+		return v, true
+	case json.Number:
+		return v.String(), true
+	case bool:
+		return strconv.FormatBool(v), true
+	}
+	return "", false
+}
+
+// parseINIConfig parses an INI-style configuration file. Every section
+// corresponds to a verb, nested verbs are separated by dots
+// (`[cluster.node]`). Comments start with `;` or `#`. Keys can be
+// repeated to specify multiple values.
+func parseINIConfig(data []byte) (*configSection, error) {
+	root := newConfigSection()
+	cs := root
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for l := 1; scanner.Scan(); l++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+		if line[0] == '[' {
+			if line[len(line)-1] != ']' {
+				return nil, fmt.Errorf("%d: Invalid section header %s", l, line)
+			}
+			cs = root.section(strings.Split(strings.TrimSpace(line[1:len(line)-1]), ".")...)
+			continue
+		}
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			return nil, fmt.Errorf("%d: Expected key = value", l)
+		}
+		cs.add(strings.TrimSpace(line[:idx]), unquote(strings.TrimSpace(line[idx+1:])), l)
+	}
+	return root, scanner.Err()
+}
+
+// parseDotenvConfig parses a dotenv file (`KEY=VALUE` lines, optionally
+// prefixed with `export`). Keys are matched against the environment
+// variable names of all flags first. Otherwise they are interpreted as long
+// flag names of the top-level FlagSet (`DRY_RUN` for `--dry-run`).
+func (fs *FlagSet) parseDotenvConfig(data []byte) (*configSection, error) {
+	envFlags := make(map[string]*Flag)
+	fs.walk(func(set *FlagSet) {
+		for _, f := range set.Flags {
+			if name := f.EnvVar(); len(name) > 0 {
+				envFlags[name] = f
+			}
+		}
+	})
+
+	root := newConfigSection()
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for l := 1; scanner.Scan(); l++ {
+		line := strings.TrimSpace(scanner.Text())
+		if len(line) == 0 || line[0] == '#' {
+			continue
+		}
+		line = strings.TrimSpace(strings.TrimPrefix(line, "export "))
+		idx := strings.Index(line, "=")
+		if idx == -1 {
+			return nil, fmt.Errorf("%d: Expected KEY=VALUE", l)
+		}
+		key, value := strings.TrimSpace(line[:idx]), unquote(strings.TrimSpace(line[idx+1:]))
+		f, ok := envFlags[key]
+		if !ok {
+			root.add(strings.ToLower(strings.Replace(key, "_", "-", -1)), value, l)
+			continue
+		}
+		cs := root.section(f.flagSet.verbPath()...)
+		_, hasSep := f.optionMeta["sep"]
+		if !f.IsMulti() || hasSep {
+			cs.add(f.Long, value, l)
+			continue
+		}
+		parts, err := splitList(value, fs.envSeparator())
+		if err != nil {
+			return nil, fmt.Errorf("%d: %s", l, err)
+		}
+		for _, part := range parts {
+			cs.add(f.Long, part, l)
+		}
+	}
+	return root, scanner.Err()
+}
+
+// unquote removes matching single or double quotes around s.
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
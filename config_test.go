@@ -0,0 +1,258 @@
+package goptions
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, name, content string) (string, func()) {
+	dir, err := ioutil.TempDir("", "goptions")
+	if err != nil {
+		t.Fatalf("Could not create temporary directory: %s", err)
+	}
+	path := filepath.Join(dir, name)
+	err = ioutil.WriteFile(path, []byte(content), 0644)
+	if err != nil {
+		t.Fatalf("Could not write config file: %s", err)
+	}
+	return path, func() { os.RemoveAll(dir) }
+}
+
+type configOptions struct {
+	Config  ConfigFile    `goptions:"-c, --config"`
+	Name    string        `goptions:"-n, --name, obligatory"`
+	Timeout time.Duration `goptions:"-t, --timeout"`
+	Servers []string      `goptions:"-s, --server"`
+	Color   bool          `goptions:"--color, negatable"`
+
+	Verbs
+	Delete struct {
+		Force bool `goptions:"-f, --force"`
+	} `goptions:"delete"`
+}
+
+func TestConfig_JSON(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	path, cleanup := writeConfig(t, "config.json", `{
+		"name": "SomeName",
+		"timeout": "3s",
+		"server": ["a", "b"],
+		"color": true,
+		"delete": {
+			"force": true
+		}
+	}`)
+	defer cleanup()
+
+	args = []string{"-c", path, "-t", "5s", "delete"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "SomeName" &&
+		options.Timeout == 5*time.Second &&
+		reflect.DeepEqual(options.Servers, []string{"a", "b"}) &&
+		options.Color &&
+		options.Delete.Force) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestConfig_Map(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Config ConfigFile        `goptions:"-c, --config"`
+		Labels map[string]string `goptions:"-l, --label"`
+		Ports  map[string]int    `goptions:"-p, --port"`
+	}
+
+	path, cleanup := writeConfig(t, "config.json", `{
+		"label": {"env": "prod", "team": "core"},
+		"port": {"http": 80}
+	}`)
+	defer cleanup()
+
+	args = []string{"-c", path, "-p", "https=443"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(reflect.DeepEqual(options.Labels, map[string]string{"env": "prod", "team": "core"}) &&
+		reflect.DeepEqual(options.Ports, map[string]int{"https": 443})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestConfig_INI(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	path, cleanup := writeConfig(t, "config.ini", `
+; Global options
+name = "SomeName"
+server = a
+server = b
+no-color = true
+
+[delete]
+force = yes
+`)
+	defer cleanup()
+
+	os.Setenv("GOPTIONS_NAME", "OtherName")
+	defer os.Unsetenv("GOPTIONS_NAME")
+
+	options.Color = true
+	args = []string{"delete"}
+	fs = NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "GOPTIONS"
+	fs.ConfigPath = path
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "OtherName" &&
+		reflect.DeepEqual(options.Servers, []string{"a", "b"}) &&
+		!options.Color &&
+		options.Delete.Force) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestConfig_UnselectedVerb(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	path, cleanup := writeConfig(t, "config.ini", "name = SomeName\n\n[delete]\nforce = maybe\n")
+	defer cleanup()
+
+	args = []string{"-c", path}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "SomeName" && !options.Delete.Force) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+	if fs.Verbs["delete"].FlagByName("--force").WasSpecified {
+		t.Fatalf("Flag of unselected verb was marked as specified")
+	}
+}
+
+func TestConfig_Dotenv(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	path, cleanup := writeConfig(t, "config.env", `
+# Global options
+export NAME=SomeName
+MYTOOL_TIMEOUT='1m'
+MYTOOL_DELETE_FORCE=1
+`)
+	defer cleanup()
+
+	args = []string{"--config=" + path, "delete"}
+	fs = NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "MYTOOL"
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "SomeName" &&
+		options.Timeout == time.Minute &&
+		options.Delete.Force) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestConfig_Errors(t *testing.T) {
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	for name, content := range map[string]string{
+		"invalid_value.ini":  "name = x\n\ntimeout = 3 seconds\n",
+		"unknown_key.ini":    "name = x\n\nvolume = 11\n",
+		"repeated_key.ini":   "timeout = 1s\nname = x\ntimeout = 2s\n",
+		"invalid_value.json": "{\n\"name\": \"x\",\n\"timeout\": 3\n}",
+		"unknown_verb.json":  "{\n\"name\": \"x\",\n\"create\": {}\n}",
+	} {
+		path, cleanup := writeConfig(t, name, content)
+		defer cleanup()
+
+		fs = NewFlagSet("goptions", &options)
+		err = fs.Parse([]string{"-c", path})
+		if err == nil {
+			t.Fatalf("Parsing %s should have failed", name)
+		}
+		if !strings.HasPrefix(err.Error(), path+":") {
+			t.Fatalf("Unexpected error for %s: %s", name, err)
+		}
+		if !strings.Contains(name, "verb") && !strings.HasPrefix(err.Error(), path+":3:") {
+			t.Fatalf("Unexpected line number for %s: %s", name, err)
+		}
+	}
+}
+
+func TestConfig_RepeatedValueErrors(t *testing.T) {
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Config ConfigFile     `goptions:"-c, --config"`
+		Ports  []int          `goptions:"-p, --port"`
+		Limits map[string]int `goptions:"-l, --limit"`
+	}
+
+	for name, content := range map[string]string{
+		"slice.ini": "port = 1\n\nport = x\n",
+		"map.ini":   "[limit]\na = 1\nb = x\n",
+	} {
+		path, cleanup := writeConfig(t, name, content)
+		defer cleanup()
+
+		fs = NewFlagSet("goptions", &options)
+		err = fs.Parse([]string{"-c", path})
+		if err == nil || !strings.HasPrefix(err.Error(), path+":3: ") {
+			t.Fatalf("Unexpected error for %s: %v", name, err)
+		}
+	}
+}
+
+func TestConfig_MissingFile(t *testing.T) {
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	options.Config = "does_not_exist.ini"
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse([]string{"-n", "SomeName"})
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse([]string{"-n", "SomeName", "-c", "does_not_exist.ini"})
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
@@ -0,0 +1,74 @@
+package goptions
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// EnvVar returns the name of the environment variable the flag's value is
+// taken from if it has not been specified on the command line. If the flag
+// has no `env` option, the name is derived from the FlagSet's EnvPrefix,
+// the names of the verbs and the long name of the flag. An empty string is
+// returned if the flag cannot be set from the environment.
+func (f *Flag) EnvVar() string {
+	if name, ok := f.optionMeta["env"].(string); ok {
+		return name
+	}
+	if f.flagSet == nil || len(f.Long) == 0 || !f.acceptsEnv() {
+		return ""
+	}
+	prefix := f.flagSet.root().EnvPrefix
+	if len(prefix) == 0 {
+		return ""
+	}
+	parts := append([]string{prefix}, f.flagSet.verbPath()...)
+	parts = append(parts, f.Long)
+	return strings.ToUpper(strings.Replace(strings.Join(parts, "_"), "-", "_", -1))
+}
+
+// acceptsEnv returns false for flags which cannot be set from a string
+// outside of the command line like the help flag or decrementing flags.
+func (f *Flag) acceptsEnv() bool {
+	if _, ok := f.value.Interface().(Help); ok {
+		return false
+	}
+	return !f.isDecrement()
+}
+
+func (fs *FlagSet) envSeparator() string {
+	if sep := fs.root().EnvSeparator; len(sep) > 0 {
+		return sep
+	}
+	return ","
+}
+
+// applyEnv sets all flags of the FlagSet which have not been specified
+// on the command line from their environment variables. Flags in a
+// MutexGroup with a flag specified on the command line are skipped. Empty
+// environment variables are ignored.
+func (fs *FlagSet) applyEnv() error {
+	shadowed := fs.shadowedFlags()
+	for _, f := range fs.Flags {
+		name := f.EnvVar()
+		if len(name) == 0 || f.WasSpecified || shadowed[f] {
+			continue
+		}
+		value := os.Getenv(name)
+		if len(value) == 0 {
+			continue
+		}
+		f.resetDefault()
+		err := f.setExternalValue(value, fs.envSeparator())
+		if err != nil {
+			err = fs.addContext(f.conversionError(value, err), -1)
+			err = fs.recoverError(fmt.Errorf("Environment variable %s: %w", name, err))
+			if err != nil {
+				return err
+			}
+		}
+		f.WasSpecified = true
+		f.Source = Source{Kind: SourceEnv, Name: name}
+	}
+	return nil
+}
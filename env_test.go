@@ -0,0 +1,181 @@
+package goptions
+
+import (
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestEnv_Option(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Timeout time.Duration `goptions:"-t, --timeout, env='GOPTIONS_TEST_TIMEOUT'"`
+		Name    string        `goptions:"-n, --name, env='GOPTIONS_TEST_NAME', obligatory"`
+	}
+
+	os.Setenv("GOPTIONS_TEST_TIMEOUT", "3s")
+	os.Setenv("GOPTIONS_TEST_NAME", "SomeName")
+	defer os.Unsetenv("GOPTIONS_TEST_TIMEOUT")
+	defer os.Unsetenv("GOPTIONS_TEST_NAME")
+
+	args = []string{"-n", "OtherName"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Timeout == 3*time.Second &&
+		options.Name == "OtherName") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Name != "SomeName" {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	os.Setenv("GOPTIONS_TEST_TIMEOUT", "3 seconds")
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestEnv_Prefix(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Servers []string `goptions:"-s, --server"`
+		Force   bool     `goptions:"--force-all"`
+		Verbose int      `goptions:"-v, count"`
+		Help    Help     `goptions:"-h, --help"`
+
+		Verbs
+		Delete struct {
+			Name string `goptions:"-n, --name, obligatory"`
+		} `goptions:"delete"`
+	}
+
+	os.Setenv("MYTOOL_SERVER", "a:b")
+	os.Setenv("MYTOOL_FORCE_ALL", "yes")
+	os.Setenv("MYTOOL_DELETE_NAME", "SomeName")
+	os.Setenv("MYTOOL_HELP", "1")
+	defer os.Unsetenv("MYTOOL_SERVER")
+	defer os.Unsetenv("MYTOOL_FORCE_ALL")
+	defer os.Unsetenv("MYTOOL_DELETE_NAME")
+	defer os.Unsetenv("MYTOOL_HELP")
+
+	args = []string{"delete"}
+	fs = NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "MYTOOL"
+	fs.EnvSeparator = ":"
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(reflect.DeepEqual(options.Servers, []string{"a", "b"}) &&
+		options.Force &&
+		options.Delete.Name == "SomeName") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	if name := fs.Verbs["delete"].Flags[0].EnvVar(); name != "MYTOOL_DELETE_NAME" {
+		t.Fatalf("Unexpected environment variable name: %s", name)
+	}
+	if name := fs.FlagByName("-h").EnvVar(); name != "" {
+		t.Fatalf("Unexpected environment variable name: %s", name)
+	}
+}
+
+func TestEnv_MutexGroup(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Create bool `goptions:"-c, mutexgroup='action', env='GOPTIONS_TEST_CREATE'"`
+		Delete bool `goptions:"-d, mutexgroup='action', obligatory"`
+	}
+
+	os.Setenv("GOPTIONS_TEST_CREATE", "true")
+	defer os.Unsetenv("GOPTIONS_TEST_CREATE")
+
+	args = []string{}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+
+	options.Create = false
+	args = []string{"-d"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(!options.Create && options.Delete) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestEnv_Decrement(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbosity int `goptions:"-v, count, decrement='-q', env='GOPTIONS_TEST_VERBOSITY', obligatory"`
+	}
+
+	os.Setenv("GOPTIONS_TEST_VERBOSITY", "2")
+	defer os.Unsetenv("GOPTIONS_TEST_VERBOSITY")
+
+	args = []string{"-q"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Verbosity != -1 {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+	if source := fs.FlagByName("-v").Source; source != (Source{Kind: SourceArgs, Position: 0}) {
+		t.Fatalf("Unexpected source: %#v", source)
+	}
+}
+
+func TestEnv_Sep(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Servers []string `goptions:"-s, --server"`
+		Paths   []string `goptions:"-p, --path, sep=':'"`
+	}
+
+	os.Setenv("GOPTIONS_TEST_SERVER", `a,"b,c"`)
+	os.Setenv("GOPTIONS_TEST_PATH", "/bin:/usr/bin")
+	defer os.Unsetenv("GOPTIONS_TEST_SERVER")
+	defer os.Unsetenv("GOPTIONS_TEST_PATH")
+
+	args = []string{}
+	fs = NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "GOPTIONS_TEST"
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(reflect.DeepEqual(options.Servers, []string{"a", "b,c"}) &&
+		reflect.DeepEqual(options.Paths, []string{"/bin", "/usr/bin"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
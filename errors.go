@@ -0,0 +1,370 @@
+package goptions
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// A FieldError describes a problem with a struct field found while creating
+// a FlagSet.
+type FieldError struct {
+	// Path of the field including the fields of all parent verbs
+	// (e.g. "Delete.Force").
+	Field string
+	Err   error
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("Invalid struct field %s: %s", e.Field, e.Err)
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// Errors is a list of errors which have occurred at once.
+type Errors []error
+
+func (e Errors) Error() string {
+	msgs := make([]string, 0, len(e))
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// Unwrap allows errors.Is() and errors.As() to inspect every error.
+func (e Errors) Unwrap() []error {
+	return e
+}
+
+// isSupported returns true if values of the flag's type can be parsed.
+func (f *Flag) isSupported() bool {
+	if f.isCounter() {
+		return true
+	}
+	t := f.value.Type()
+	if isSupportedType(t) {
+		return true
+	}
+	if t.Kind() == reflect.Slice || t.Kind() == reflect.Map {
+		return isSupportedType(t.Elem())
+	}
+	return false
+}
+
+func isSupportedType(t reflect.Type) bool {
+	if _, ok := parserMap[t]; ok {
+		return true
+	}
+	return t.Implements(reflect.TypeOf(new(Marshaler)).Elem())
+}
+
+// names returns all names the flag can be given with on the command line.
+func (f *Flag) names() []string {
+	r := []string{}
+	if len(f.Short) > 0 {
+		r = append(r, "-"+f.Short)
+	}
+	if len(f.Long) > 0 {
+		r = append(r, "--"+f.Long)
+	}
+	if f.Negatable {
+		r = append(r, "--no-"+f.Long)
+	}
+	return r
+}
+
+// fieldPath returns the path of the struct field with the given index
+// including the fields of all parent verbs.
+func (fs *FlagSet) fieldPath(index int) string {
+	name := fs.structValue.Type().Field(index).Name
+	if fs.parent == nil {
+		return name
+	}
+	return fs.parent.fieldPath(fs.index) + "." + name
+}
+
+// checkNames reports flags of the FlagSet and its verbs which share a name
+// with another flag of the same FlagSet or with a persistent flag of a
+// parent.
+func (fs *FlagSet) checkNames() Errors {
+	var errs Errors
+	defined := make(map[string]*Flag)
+	for set := fs.parent; set != nil; set = set.parent {
+		for _, f := range set.Flags {
+			for _, name := range f.names() {
+				if _, ok := defined[name]; !ok && f.Persistent {
+					defined[name] = f
+				}
+			}
+		}
+	}
+	for _, f := range fs.Flags {
+		for _, name := range f.names() {
+			if other, ok := defined[name]; ok && other != f {
+				errs = append(errs, &FieldError{
+					Field: fs.fieldPath(f.index),
+					Err:   fmt.Errorf("Flag %s is already defined by %s", name, other.flagSet.fieldPath(other.index)),
+				})
+			}
+			defined[name] = f
+		}
+	}
+	names := make([]string, 0, len(fs.Verbs))
+	for name := range fs.Verbs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		errs = append(errs, fs.Verbs[name].checkNames()...)
+	}
+	return errs
+}
+
+// ErrorContext describes where an error returned by Parse() occurred.
+type ErrorContext struct {
+	// Flag or positional argument the error refers to. nil if the error
+	// does not refer to a single known flag.
+	Flag *Flag
+	// Names of the verbs which had been selected when the error occurred.
+	Verbs []string
+	// Index of the offending argument in the arguments passed to Parse().
+	// -1 if the error is not caused by a single argument.
+	Index int
+}
+
+func (c *ErrorContext) context() *ErrorContext {
+	return c
+}
+
+type contextError interface {
+	context() *ErrorContext
+}
+
+// UnknownFlagError is returned if an argument looks like a flag but is not
+// defined.
+type UnknownFlagError struct {
+	ErrorContext
+	Name string
+	// All arguments which could not be processed.
+	Args []string
+	// Known flags with a similar name.
+	Suggestions []string
+}
+
+func (e *UnknownFlagError) Error() string {
+	return fmt.Sprintf("Unknown flag %s%s", e.Name, didYouMean(e.Suggestions))
+}
+
+// UnknownVerbError is returned if an argument at the position of a verb
+// does not name a verb.
+type UnknownVerbError struct {
+	ErrorContext
+	Name string
+	// All arguments which could not be processed.
+	Args []string
+	// Known verbs with a similar name.
+	Suggestions []string
+}
+
+func (e *UnknownVerbError) Error() string {
+	return fmt.Sprintf("Unknown verb %s%s", e.Name, didYouMean(e.Suggestions))
+}
+
+// TrailingArgumentsError is returned if arguments remain after all flags,
+// positional arguments and verbs have been processed.
+type TrailingArgumentsError struct {
+	ErrorContext
+	// All arguments which could not be processed.
+	Args []string
+}
+
+func (e *TrailingArgumentsError) Error() string {
+	return fmt.Sprintf("Invalid trailing arguments: %v", e.Args)
+}
+
+// AmbiguousError is returned if an abbreviated flag or verb is the prefix
+// of more than one name.
+type AmbiguousError struct {
+	ErrorContext
+	Name string
+	// Names starting with Name.
+	Candidates []string
+}
+
+func (e *AmbiguousError) Error() string {
+	kind := "verb"
+	if strings.HasPrefix(e.Name, "-") {
+		kind = "flag"
+	}
+	return fmt.Sprintf("Ambiguous %s %s, could be %s", kind, e.Name, strings.Join(e.Candidates, ", "))
+}
+
+// MissingValueError is returned if a flag is missing its value or if a
+// positional argument got less than its minimum number of arguments.
+type MissingValueError struct {
+	ErrorContext
+	// Minimum number of arguments of a positional argument.
+	Min int
+}
+
+func (e *MissingValueError) Error() string {
+	if e.Flag.isPositional() {
+		return fmt.Sprintf("%s needs at least %d arguments", e.Flag.Name(), e.Min)
+	}
+	return fmt.Sprintf("Flag %s needs an argument", e.Flag.Name())
+}
+
+// UnexpectedValueError is returned if a value is attached to a flag which
+// does not take one.
+type UnexpectedValueError struct {
+	ErrorContext
+	// Name the flag has been given with.
+	Name string
+}
+
+func (e *UnexpectedValueError) Error() string {
+	return fmt.Sprintf("Flag %s does not take an argument", e.Name)
+}
+
+// DuplicateFlagError is returned if a flag which takes a single value is
+// specified more than once.
+type DuplicateFlagError struct {
+	ErrorContext
+}
+
+func (e *DuplicateFlagError) Error() string {
+	return fmt.Sprintf("Flag %s can only be specified once", e.Flag.Name())
+}
+
+// ObligatoryError is returned if an obligatory flag or positional argument
+// has not been specified.
+type ObligatoryError struct {
+	ErrorContext
+}
+
+func (e *ObligatoryError) Error() string {
+	return fmt.Sprintf("%s must be specified", e.Flag.Name())
+}
+
+// MutexGroupError is returned if more than one flag of a MutexGroup or none
+// of an obligatory MutexGroup has been specified.
+type MutexGroupError struct {
+	ErrorContext
+	Group string
+	Flags MutexGroup
+}
+
+func (e *MutexGroupError) Error() string {
+	return fmt.Sprintf("Exactly one of %s must be specified", strings.Join(e.Flags.Names(), ", "))
+}
+
+// ConversionError is returned if a value could not be converted to the type
+// of its flag.
+type ConversionError struct {
+	ErrorContext
+	Value string
+	Err   error
+}
+
+func (e *ConversionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConversionError) Unwrap() error {
+	return e.Err
+}
+
+// errorMessage returns the message ParseAndFail prints for err. Conversion
+// errors of command line arguments carry no flag name in their message, so
+// the flag and the position of the argument are prepended.
+func errorMessage(err error) string {
+	if ce, ok := err.(*ConversionError); ok && ce.Flag != nil && ce.Index >= 0 {
+		return fmt.Sprintf("%s (argument %d): %s", ce.Flag.Name(), ce.Index, ce)
+	}
+	return err.Error()
+}
+
+// conversionError wraps an error returned while setting value.
+func (f *Flag) conversionError(value string, err error) error {
+	if err == nil || err == ErrHelpRequest {
+		return err
+	}
+	return &ConversionError{ErrorContext: ErrorContext{Flag: f, Index: -1}, Value: value, Err: err}
+}
+
+// addContext records the selected verbs and the index of the offending
+// argument in err.
+func (fs *FlagSet) addContext(err error, index int) error {
+	var ce contextError
+	if errors.As(err, &ce) {
+		c := ce.context()
+		c.Verbs = fs.verbPath()
+		c.Index = index
+	}
+	return err
+}
+
+// unknownFlagError returns the error for the unknown flag at the beginning
+// of args. index is the position of the flag.
+func (fs *FlagSet) unknownFlagError(args []string, index int) error {
+	name := args[0]
+	if isLong(name) {
+		name, _, _ = splitLong(name)
+		name = "--" + name
+	} else if isShort(name) {
+		name = name[:2]
+	}
+	return &UnknownFlagError{
+		ErrorContext: ErrorContext{Verbs: fs.verbPath(), Index: index},
+		Name:         name,
+		Args:         args,
+		Suggestions:  fs.suggestFlags(name),
+	}
+}
+
+// isFlagLike returns true if arg would be a flag if it was defined.
+// Negative numbers are not considered flags.
+func isFlagLike(arg string) bool {
+	if _, err := strconv.ParseFloat(arg, 64); err == nil {
+		return false
+	}
+	return (isShort(arg) || isLong(arg)) && arg != "--"
+}
+
+// recoverError records err and returns nil if the top-level FlagSet reports
+// all errors and parsing can continue after err. Otherwise err is returned.
+func (fs *FlagSet) recoverError(err error) error {
+	root := fs.root()
+	if !root.ReportAllErrors || !isRecoverable(err) {
+		return err
+	}
+	root.errs = append(root.errs, err)
+	return nil
+}
+
+// isRecoverable returns true for errors after which parsing can continue.
+func isRecoverable(err error) bool {
+	var conversion *ConversionError
+	var obligatory *ObligatoryError
+	var mutex *MutexGroupError
+	return errors.As(err, &conversion) || errors.As(err, &obligatory) || errors.As(err, &mutex)
+}
+
+// trailingError returns the error for arguments which could not be
+// processed. positions holds the index of each argument.
+func (fs *FlagSet) trailingError(args []string, positions []int) error {
+	c := ErrorContext{Verbs: fs.verbPath(), Index: positions[0]}
+	if isFlagLike(args[0]) {
+		return fs.unknownFlagError(args, positions[0])
+	}
+	if len(fs.Verbs) > 0 && fs.selected == nil {
+		return &UnknownVerbError{ErrorContext: c, Name: args[0], Args: args, Suggestions: fs.suggestVerbs(args[0])}
+	}
+	return &TrailingArgumentsError{ErrorContext: c, Args: args}
+}
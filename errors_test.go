@@ -0,0 +1,233 @@
+package goptions
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type errorOptions struct {
+	Name    string `goptions:"-n, --name, obligatory"`
+	Limit   int    `goptions:"-l, --limit"`
+	Verbose bool   `goptions:"-v, --verbose"`
+	Fast    bool   `goptions:"--fast, mutexgroup='speed'"`
+	Slow    bool   `goptions:"--slow, mutexgroup='speed'"`
+	Quiet   int    `goptions:"-q, --quiet, count"`
+
+	Verbs
+	Copy struct {
+		Force bool     `goptions:"-f, --force"`
+		Files []string `goptions:"pos=1, min=2"`
+	} `goptions:"copy"`
+}
+
+func TestParse_TypedErrors(t *testing.T) {
+	var options errorOptions
+	fs := NewFlagSet("goptions", &options)
+
+	var unknownFlag *UnknownFlagError
+	err := fs.Parse([]string{"-n", "x", "--bogus"})
+	if !errors.As(err, &unknownFlag) ||
+		unknownFlag.Name != "--bogus" ||
+		unknownFlag.Index != 2 ||
+		err.Error() != "Unknown flag --bogus" {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var unknownVerb *UnknownVerbError
+	err = fs.Parse([]string{"-n", "x", "move", "a"})
+	if !errors.As(err, &unknownVerb) || unknownVerb.Name != "move" || unknownVerb.Index != 2 {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var missing *MissingValueError
+	err = fs.Parse([]string{"-v", "-l"})
+	if !errors.As(err, &missing) ||
+		missing.Flag != fs.Flags[1] ||
+		missing.Index != 1 ||
+		err.Error() != "Flag --limit needs an argument" {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	err = fs.Parse([]string{"-n", "x", "copy", "a"})
+	if !errors.As(err, &missing) ||
+		missing.Min != 2 ||
+		!reflect.DeepEqual(missing.Verbs, []string{"copy"}) {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var duplicate *DuplicateFlagError
+	err = fs.Parse([]string{"-n", "x", "copy", "-f", "-f", "a", "b"})
+	if !errors.As(err, &duplicate) ||
+		duplicate.Index != 4 ||
+		!reflect.DeepEqual(duplicate.Verbs, []string{"copy"}) ||
+		err.Error() != "Flag --force can only be specified once" {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var unexpected *UnexpectedValueError
+	err = fs.Parse([]string{"--verbose=1", "--quiet=2"})
+	if !errors.As(err, &unexpected) ||
+		unexpected.Index != 1 ||
+		err.Error() != "Flag --quiet does not take an argument" {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var obligatory *ObligatoryError
+	err = fs.Parse([]string{})
+	if !errors.As(err, &obligatory) ||
+		obligatory.Flag != fs.Flags[0] ||
+		err.Error() != "--name must be specified" {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var mutex *MutexGroupError
+	err = fs.Parse([]string{"-n", "x", "--fast", "--slow"})
+	if !errors.As(err, &mutex) || mutex.Group != "speed" || len(mutex.Flags) != 2 {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	fs.Reset()
+	var conversion *ConversionError
+	err = fs.Parse([]string{"-n", "x", "-l", "ten"})
+	if !errors.As(err, &conversion) ||
+		conversion.Flag != fs.Flags[1] ||
+		conversion.Value != "ten" ||
+		conversion.Index != 2 {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+
+	var trailing *TrailingArgumentsError
+	fs = NewFlagSet("goptions", &struct {
+		Verbose bool `goptions:"-v, --verbose"`
+	}{})
+	err = fs.Parse([]string{"-v", "a", "b"})
+	if !errors.As(err, &trailing) ||
+		trailing.Index != 1 ||
+		!reflect.DeepEqual(trailing.Args, []string{"a", "b"}) ||
+		err.Error() != "Invalid trailing arguments: [a b]" {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+}
+
+func TestParse_ReportAllErrors(t *testing.T) {
+	var options struct {
+		Name  string `goptions:"-n, --name, obligatory"`
+		Limit int    `goptions:"-l, --limit"`
+		Fast  bool   `goptions:"--fast, mutexgroup='speed'"`
+		Slow  bool   `goptions:"--slow, mutexgroup='speed'"`
+
+		Verbs
+		Copy struct {
+			Retries int    `goptions:"-r, --retries"`
+			Target  string `goptions:"-t, --target, obligatory"`
+		} `goptions:"copy"`
+	}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.ReportAllErrors = true
+	err := fs.Parse([]string{"-l", "ten", "--fast", "--slow", "copy", "-r", "x"})
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+	expected := []string{
+		`strconv.ParseInt: parsing "ten": invalid syntax`,
+		`strconv.ParseInt: parsing "x": invalid syntax`,
+		"--name must be specified",
+		"Exactly one of --fast, --slow must be specified",
+		"--target must be specified",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Unexpected errors: %s", err)
+	}
+	for i, e := range errs {
+		if e.Error() != expected[i] {
+			t.Fatalf("Unexpected error: %s", e)
+		}
+	}
+
+	expected = []string{
+		`--limit (argument 0): strconv.ParseInt: parsing "ten": invalid syntax`,
+		`--retries (argument 5): strconv.ParseInt: parsing "x": invalid syntax`,
+		"--name must be specified",
+	}
+	for i, e := range expected {
+		if msg := errorMessage(errs[i]); msg != e {
+			t.Fatalf("Unexpected message: %s", msg)
+		}
+	}
+
+	fs.Reset()
+	fs.ReportAllErrors = false
+	err = fs.Parse([]string{"-l", "ten", "--fast", "--slow"})
+	if _, ok := err.(Errors); ok || err.Error() != `strconv.ParseInt: parsing "ten": invalid syntax` {
+		t.Fatalf("Unexpected error: %#v", err)
+	}
+}
+
+func TestParse_ClusterNumber(t *testing.T) {
+	var options struct {
+		Verbose bool `goptions:"-v"`
+		N       int  `goptions:"pos=1"`
+	}
+
+	for name, arg := range map[string]string{"-1": "-v1", "-i": "-vinf", "-n": "-vnan"} {
+		fs := NewFlagSet("goptions", &options)
+		err := fs.Parse([]string{arg})
+		if unknown, ok := err.(*UnknownFlagError); !ok || unknown.Name != name || unknown.Index != 0 {
+			t.Fatalf("Unexpected error for %s: %#v", arg, err)
+		}
+	}
+
+	fs := NewFlagSet("goptions", &options)
+	err := fs.Parse([]string{"-v", "-1"})
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbose && options.N == -1) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_Suggestions(t *testing.T) {
+	var options struct {
+		Timeout int  `goptions:"-t, --timeout, persistent"`
+		Color   bool `goptions:"--color, negatable"`
+		Debug   bool `goptions:"--debug"`
+
+		Verbs
+		Copy struct {
+			Force bool `goptions:"-f, --force"`
+		} `goptions:"copy, alias='cp'"`
+		Compare struct{} `goptions:"compare"`
+		Secret  struct{} `goptions:"secret, hidden"`
+	}
+
+	for expected, args := range map[string][]string{
+		"Unknown flag --tiemout, did you mean --timeout?":  {"--tiemout=5"},
+		"Unknown flag --no-colr, did you mean --no-color?": {"--no-colr"},
+		"Unknown flag --x": {"-t", "1", "--x"},
+		"Unknown flag -x":  {"-x"},
+		"Unknown flag --forse, did you mean --force?":    {"copy", "--forse"},
+		"Unknown flag --timeot, did you mean --timeout?": {"copy", "--timeot"},
+		"Unknown flag --debg":                            {"copy", "--debg"},
+		"Unknown verb copi, did you mean copy?":          {"copi"},
+		"Unknown verb cpy, did you mean copy or cp?":     {"cpy"},
+		"Unknown verb secrets":                           {"secrets"},
+		"Unknown verb compar, did you mean compare?":     {"compar"},
+	} {
+		fs := NewFlagSet("goptions", &options)
+		err := fs.Parse(args)
+		if err == nil || err.Error() != expected {
+			t.Fatalf("Unexpected error for %v: %v", args, err)
+		}
+	}
+}
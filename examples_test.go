@@ -54,6 +54,182 @@ func ExampleFlagSet_PrintHelp() {
 	//             --script   Script to exectute
 }
 
+func ExampleFlagSet_PrintHelp_negatable() {
+	options := struct {
+		Color bool `goptions:"-c, --color, negatable, description='Colorize output'"`
+		Force bool `goptions:"-f, --force, description='Force removal'"`
+	}{ // Default values goes here
+		Color: true,
+	}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options]
+	//
+	// Global options:
+	//         -c, --[no-]color Colorize output (default: true)
+	//         -f, --force      Force removal
+}
+
+func ExampleFlagSet_PrintHelp_counter() {
+	options := struct {
+		Verbosity int `goptions:"-v, --verbose, count, decrement='-q, --quiet', description='Increase verbosity'"`
+	}{}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options]
+	//
+	// Global options:
+	//         -v, --verbose Increase verbosity
+	//         -q, --quiet   Decrement --verbose
+}
+
+func ExampleFlagSet_PrintHelp_optional() {
+	options := struct {
+		Color string `goptions:"--color, optional='auto', placeholder='WHEN', description='Colorize output'"`
+		Level int    `goptions:"-l, --level, optional='1', description='Compression level'"`
+		Depth int    `goptions:"-d, optional='1', placeholder='DEPTH', description='Recursion depth'"`
+	}{}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options]
+	//
+	// Global options:
+	//                    --color[=WHEN]  Colorize output
+	//         -l,        --level[=LEVEL] Compression level
+	//         -d[DEPTH],                 Recursion depth
+}
+
+func ExampleFlagSet_PrintHelp_map() {
+	options := struct {
+		Labels  map[string]string `goptions:"-l, --label, description='Add a label'"`
+		Headers map[string]string `goptions:"--header, kvsep=':', description='Add a header'"`
+	}{}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options]
+	//
+	// Global options:
+	//         -l, --label KEY=VALUE  Add a label
+	//             --header KEY:VALUE Add a header
+}
+
+func ExampleFlagSet_PrintHelp_env() {
+	options := struct {
+		Server  string        `goptions:"-s, --server, obligatory, description='Server to connect to'"`
+		Timeout time.Duration `goptions:"-t, --timeout, env='TIMEOUT', description='Connection timeout'"`
+		Token   string        `goptions:"--token, secret, description='API token'"`
+	}{
+		Token: "hunter2",
+	}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "MYTOOL"
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options]
+	//
+	// Global options:
+	//         -s, --server  Server to connect to [$MYTOOL_SERVER] (*)
+	//         -t, --timeout Connection timeout [$TIMEOUT]
+	//             --token   API token [$MYTOOL_TOKEN]
+}
+
+func ExampleFlagSet_PrintHelp_nestedVerbs() {
+	options := struct {
+		Verbose bool `goptions:"-v, --verbose, description='Be verbose'"`
+
+		Verbs
+		Cluster struct {
+			Name string `goptions:"-n, --name, description='Name of the cluster'"`
+
+			Verbs
+			Node struct {
+				Verbs
+				Add struct {
+					Force bool `goptions:"-f, --force, description='Add even if unreachable'"`
+				} `goptions:"add"`
+				Remove struct {
+					Drain bool `goptions:"--drain, description='Drain before removal'"`
+				} `goptions:"remove"`
+			} `goptions:"node"`
+		} `goptions:"cluster"`
+	}{}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options] <verb> [verb options]
+	//
+	// Global options:
+	//         -v, --verbose Be verbose
+	//
+	// Verbs:
+	//     cluster:
+	//         -n, --name    Name of the cluster
+	//     cluster node:
+	//     cluster node add:
+	//         -f, --force   Add even if unreachable
+	//     cluster node remove:
+	//             --drain   Drain before removal
+}
+
+func ExampleFlagSet_PrintHelp_verbDescriptions() {
+	options := struct {
+		Verbs
+		Delete struct {
+			Force bool `goptions:"-f, --force, description='Force removal'"`
+		} `goptions:"delete, alias='rm', description='Delete an entity'"`
+		Debug struct{} `goptions:"debug, hidden"`
+		List  struct{} `goptions:"list, description='List all entities'"`
+	}{}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options] <verb> [verb options]
+	//
+	// Global options:
+	//
+	// Verbs:
+	//     delete: Delete an entity (aliases: rm)
+	//         -f, --force Force removal
+	//     list: List all entities
+}
+
+func ExampleFlagSet_PrintHelp_positionals() {
+	options := struct {
+		Force  bool     `goptions:"-f, --force, description='Overwrite existing files'"`
+		Source *os.File `goptions:"pos=1, name='SOURCE', obligatory, rdonly, description='File to copy'"`
+		Dests  []string `goptions:"pos=2, name='DEST', description='Destinations'"`
+	}{}
+
+	fs := NewFlagSet("goptions", &options)
+	fs.PrintHelp(os.Stdout)
+
+	// Output:
+	// Usage: goptions [global options] SOURCE [DEST...]
+	//
+	// Global options:
+	//         -f, --force Overwrite existing files
+	//             SOURCE  File to copy (*)
+	//             DEST    Destinations
+}
+
 func ExampleVerbs() {
 	options := struct {
 		ImportantFlag string        `goptions:"-f, --flag, description='Important flag, obligatory'"`
@@ -13,16 +13,28 @@ type Flag struct {
 	MutexGroups  []string
 	Description  string
 	Obligatory   bool
+	Negatable    bool
+	Persistent   bool
 	WasSpecified bool
+	// Source describes where the flag's value came from.
+	Source       Source
 	value        reflect.Value
 	optionMeta   map[string]interface{}
 	DefaultValue interface{}
+	flagSet      *FlagSet
+	// Counter flag changed by a decrement flag
+	counter *Flag
+	// Index of the struct field holding the value
+	index int
 }
 
 // Return the name of the flag preceding the right amount of dashes.
 // The long name is preferred. If no name has been specified, "<unspecified>"
-// will be returned.
+// will be returned. For positional arguments, the placeholder is returned.
 func (f *Flag) Name() string {
+	if f.isPositional() {
+		return f.Placeholder()
+	}
 	if len(f.Long) > 0 {
 		return "--" + f.Long
 	}
@@ -35,24 +47,106 @@ func (f *Flag) Name() string {
 // NeedsExtraValue returns true if the flag expects a separate value.
 func (f *Flag) NeedsExtraValue() bool {
 	// Explicit over implicit
-	if f.value.Type() == reflect.TypeOf(new([]bool)).Elem() ||
-		f.value.Type() == reflect.TypeOf(new(bool)).Elem() {
+	if f.isBool() {
 		return false
 	}
 	if _, ok := f.value.Interface().(Help); ok {
 		return false
 	}
+	if f.isCounter() || f.IsOptional() {
+		return false
+	}
 	return true
 }
 
+// IsSecret returns true if the flag's value must not be shown, neither by
+// FlagSet.Explain() nor as the default value in the help.
+func (f *Flag) IsSecret() bool {
+	_, ok := f.optionMeta["secret"]
+	return ok
+}
+
+// IsOptional returns true if the flag takes a value, but only if it is
+// attached to the flag (`--flag=value`). Otherwise the value given by the
+// `optional` option is used.
+func (f *Flag) IsOptional() bool {
+	_, ok := f.optionMeta["optional"]
+	return ok
+}
+
+// Placeholder returns the name of the flag's value as it is shown in the
+// help. It defaults to the upper-cased long name or `KEY=VALUE` for maps.
+func (f *Flag) Placeholder() string {
+	if p, ok := f.optionMeta["placeholder"].(string); ok {
+		return p
+	}
+	if f.IsMap() {
+		return "KEY" + f.keyValueSeparator() + "VALUE"
+	}
+	if len(f.Long) > 0 {
+		return strings.ToUpper(strings.Replace(f.Long, "-", "_", -1))
+	}
+	if f.isPositional() {
+		return fmt.Sprintf("ARG%d", f.optionMeta["pos"])
+	}
+	return "VALUE"
+}
+
 // IsMulti returns true if the flag can be specified multiple times.
 func (f *Flag) IsMulti() bool {
-	if f.value.Kind() == reflect.Slice {
+	if f.value.Kind() == reflect.Slice || f.IsMap() || f.isCounter() {
 		return true
 	}
 	return false
 }
 
+// IsMap returns true if every occurrence of the flag adds a `key=value`
+// pair to a map.
+func (f *Flag) IsMap() bool {
+	return f.value.Kind() == reflect.Map
+}
+
+// isCounter returns true if every occurrence of the flag changes the
+// value of an integer by a fixed step.
+func (f *Flag) isCounter() bool {
+	_, ok := f.optionMeta["count"]
+	return ok
+}
+
+// isDecrement returns true if the flag decrements another flag's counter.
+func (f *Flag) isDecrement() bool {
+	step, ok := f.optionMeta["count"].(int)
+	return ok && step < 0
+}
+
+// isBool returns true if the flag is a bool or a slice of bools.
+func (f *Flag) isBool() bool {
+	t := f.value.Type()
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t == reflect.TypeOf(new(bool)).Elem()
+}
+
+// isNegation returns true if name is the negated long name of the flag.
+func (f *Flag) isNegation(name string) bool {
+	return f.Negatable && name == "no-"+f.Long
+}
+
+// resetDefault removes the default values of a slice or map before the
+// first value is added. With the `append` option, the values are added to
+// a copy of the default instead.
+func (f *Flag) resetDefault() {
+	if f.WasSpecified || !(f.value.Kind() == reflect.Slice || f.IsMap()) {
+		return
+	}
+	if _, ok := f.optionMeta["append"]; ok {
+		f.value.Set(copyValue(f.value))
+		return
+	}
+	f.value.Set(reflect.Zero(f.value.Type()))
+}
+
 func isShort(arg string) bool {
 	return strings.HasPrefix(arg, "-") && !strings.HasPrefix(arg, "--") && len(arg) >= 2
 }
@@ -61,30 +155,83 @@ func isLong(arg string) bool {
 	return strings.HasPrefix(arg, "--") && len(arg) >= 3
 }
 
-func (f *Flag) Handles(arg string) bool {
-	return (isShort(arg) && arg[1:2] == f.Short) ||
-		(isLong(arg) && arg[2:] == f.Long)
+// splitLong splits a long flag argument in the form of `--name=value` into
+// its name and value. hasValue reports whether a value was attached.
+func splitLong(arg string) (name, value string, hasValue bool) {
+	name = arg[2:]
+	if idx := strings.Index(name, "="); idx != -1 {
+		return name[:idx], name[idx+1:], true
+	}
+	return name, "", false
+}
 
+func (f *Flag) Handles(arg string) bool {
+	if isLong(arg) {
+		name, _, _ := splitLong(arg)
+		return name == f.Long || f.isNegation(name)
+	}
+	return isShort(arg) && arg[1:2] == f.Short
 }
 
+// Parse sets the flag's value from the beginning of args and returns the
+// arguments which have not been consumed. args is not modified.
 func (f *Flag) Parse(args []string) ([]string, error) {
+	args, _, err := f.parse(args)
+	return args, err
+}
+
+// parse is like Parse but additionally returns the value given for the
+// flag. A flag in the middle of a short flag cluster is replaced by the
+// rest of the cluster in the returned arguments.
+func (f *Flag) parse(args []string) ([]string, string, error) {
 	param, value := args[0], ""
-	if f.NeedsExtraValue() &&
-		(len(args) < 2 || (isShort(param) && len(param) > 2)) {
-		return args, fmt.Errorf("Flag %s needs an argument", f.Name())
+	// The last of --foo, --no-foo and --foo=BOOL wins.
+	lastWins := f.Negatable && f.isBool() && f.value.Kind() != reflect.Slice
+	if f.WasSpecified && !f.IsMulti() && !lastWins {
+		return args, "", &DuplicateFlagError{ErrorContext{Flag: f, Index: -1}}
 	}
-	if f.WasSpecified && !f.IsMulti() {
-		return args, fmt.Errorf("Flag %s can only be specified once", f.Name())
+	name, attached, hasValue := "", "", false
+	if isLong(param) {
+		name, attached, hasValue = splitLong(param)
 	}
-	if isShort(param) && len(param) > 2 {
-		// Short flag cluster
-		args[0] = "-" + param[2:]
-	} else if f.NeedsExtraValue() {
+	switch {
+	case f.isNegation(name):
+		if hasValue {
+			return args, "", &UnexpectedValueError{ErrorContext{Flag: f, Index: -1}, "--" + name}
+		}
+		value = "false"
+		args = args[1:]
+	case hasValue:
+		if !f.NeedsExtraValue() && !f.isBool() && !f.IsOptional() {
+			return args, "", &UnexpectedValueError{ErrorContext{Flag: f, Index: -1}, f.Name()}
+		}
+		value = attached
+		args = args[1:]
+	case isShort(param) && len(param) > 2:
+		// Short flag cluster. A flag expecting a value consumes the rest
+		// of the cluster.
+		if f.NeedsExtraValue() || f.IsOptional() {
+			value = param[2:]
+			args = args[1:]
+		} else {
+			args = append([]string{"-" + param[2:]}, args[1:]...)
+		}
+	case f.NeedsExtraValue():
+		if len(args) < 2 {
+			return args, "", &MissingValueError{ErrorContext: ErrorContext{Flag: f, Index: -1}}
+		}
 		value = args[1]
 		args = args[2:]
-	} else {
+	default:
+		if f.IsOptional() {
+			value = f.optionMeta["optional"].(string)
+		}
 		args = args[1:]
 	}
+	f.resetDefault()
 	f.WasSpecified = true
-	return args, f.setValue(value)
+	if f.counter != nil {
+		f.counter.WasSpecified = true
+	}
+	return args, value, f.conversionError(value, f.setValue(value))
 }
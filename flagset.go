@@ -6,6 +6,7 @@ import (
 	"io"
 	"os"
 	"reflect"
+	"sort"
 	"strings"
 	"sync"
 )
@@ -16,7 +17,32 @@ type FlagSet struct {
 	// This HelpFunc will be called when PrintHelp() is called.
 	HelpFunc
 	// Name of the program. Might be used by HelpFunc.
-	Name          string
+	Name string
+	// Mode determines how arguments which are neither flags nor verbs are
+	// handled. Verb FlagSets inherit the Mode of their parent by default.
+	Mode ParseMode
+	// If EnvPrefix is set, flags which have not been specified on the command
+	// line are looked up in the environment as PREFIX_VERB_LONG_NAME. Only the
+	// EnvPrefix of the top-level FlagSet is used.
+	EnvPrefix string
+	// EnvSeparator splits the value of an environment variable into
+	// multiple values for slice flags. Defaults to ",". Only the EnvSeparator
+	// of the top-level FlagSet is used.
+	EnvSeparator string
+	// ConfigPath is the path of a configuration file providing values for
+	// flags which have been specified neither on the command line nor in
+	// the environment. A ConfigFile flag takes precedence. Only the
+	// ConfigPath of the top-level FlagSet is used.
+	ConfigPath string
+	// If ReportAllErrors is set, Parse() continues after invalid values,
+	// missing obligatory flags and violated MutexGroups and returns all
+	// of them as Errors. Only the setting of the top-level FlagSet is used.
+	ReportAllErrors bool
+	// If Abbreviations is set, long flags and verbs can be given as any
+	// unambiguous prefix of their name (e.g. `--verb` for `--verbose`).
+	// Exact matches take priority. Only the setting of the top-level
+	// FlagSet is used.
+	Abbreviations bool
 	helpFlag      *Flag
 	remainderFlag *Flag
 	shortMap      map[string]*Flag
@@ -24,37 +50,71 @@ type FlagSet struct {
 	verbFlag      *Flag
 	// Global option flags
 	Flags []*Flag
+	// Positional arguments, ordered by position
+	Positionals []*Flag
 	// Verbs and corresponding FlagSets
-	Verbs  map[string]*FlagSet
-	parent *FlagSet
+	Verbs map[string]*FlagSet
+	// Description of the verb. Will be used by the HelpFunc.
+	Description string
+	// Alternative names of the verb.
+	Aliases []string
+	// Hidden verbs are not shown by the HelpFunc.
+	Hidden      bool
+	verbAliases map[string]string
+	parent      *FlagSet
+	selected    *FlagSet
+	tokens      []Token
+	errs        Errors
+	structValue reflect.Value
+	// Index of the verb's field in the parent's struct
+	index int
 }
 
 // NewFlagSet returns a new FlagSet containing all the flags which result from
 // parsing the tags of the struct. Said struct as to be passed to the function
 // as a pointer.
 // If a tag line is erroneous, NewFlagSet() panics as this is considered a
-// compile time error rather than a runtme error.
+// compile time error rather than a runtme error. Use NewFlagSetE() to get
+// an error instead.
 func NewFlagSet(name string, v interface{}) *FlagSet {
+	fs, err := NewFlagSetE(name, v)
+	if err != nil {
+		panic(err.Error())
+	}
+	return fs
+}
+
+// NewFlagSetE is like NewFlagSet but returns an error instead of panicking.
+// All problems found in the struct are reported at once as Errors. Problems
+// of single struct fields are reported as a *FieldError.
+func NewFlagSetE(name string, v interface{}) (*FlagSet, error) {
 	structValue := reflect.ValueOf(v)
-	if structValue.Kind() != reflect.Ptr {
-		panic("Value type is not a pointer to a struct")
+	if structValue.Kind() != reflect.Ptr || structValue.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("Value type is not a pointer to a struct")
 	}
-	structValue = structValue.Elem()
-	if structValue.Kind() != reflect.Struct {
-		panic("Value type is not a pointer to a struct")
+	fs, errs := newFlagset(name, structValue.Elem(), nil)
+	errs = append(errs, fs.checkNames()...)
+	if len(errs) > 0 {
+		return nil, errs
 	}
-	return newFlagset(name, structValue, nil)
+	return fs, nil
 }
 
 // Internal version which skips type checking and takes the "parent"'s
-// remainder flag as a parameter.
-func newFlagset(name string, structValue reflect.Value, parent *FlagSet) *FlagSet {
+// remainder flag as a parameter. All problems found in the struct are
+// returned.
+func newFlagset(name string, structValue reflect.Value, parent *FlagSet) (*FlagSet, Errors) {
 	var once sync.Once
+	var errs Errors
 	r := &FlagSet{
-		Name:     name,
-		Flags:    make([]*Flag, 0),
-		HelpFunc: DefaultHelpFunc,
-		parent:   parent,
+		Name:        name,
+		Flags:       make([]*Flag, 0),
+		HelpFunc:    DefaultHelpFunc,
+		parent:      parent,
+		structValue: structValue,
+	}
+	fieldError := func(i int, err error) {
+		errs = append(errs, &FieldError{Field: structValue.Type().Field(i).Name, Err: err})
 	}
 
 	if parent != nil && parent.remainderFlag != nil {
@@ -72,37 +132,104 @@ func newFlagset(name string, structValue reflect.Value, parent *FlagSet) *FlagSe
 		fieldValue := structValue.Field(i)
 		tag := structValue.Type().Field(i).Tag.Get("goptions")
 		flag, err := parseStructField(fieldValue, tag)
-
-		if err != nil {
-			panic(fmt.Sprintf("Invalid struct field: %s", err))
+		if err == nil && flag.isPositional() && isSpecialType(fieldValue.Type()) {
+			err = fmt.Errorf("Option pos is invalid for type %s", fieldValue.Type())
 		}
 		if fieldValue.Type().Name() == "Verbs" {
+			if err != nil {
+				fieldError(i, err)
+				break
+			}
+			flag.index = i
 			r.verbFlag = flag
 			break
 		}
+		if err != nil {
+			fieldError(i, err)
+			continue
+		}
+		flag.index = i
 		if fieldValue.Type().Name() == "Help" {
 			r.helpFlag = flag
 		}
-		if fieldValue.Type().Name() == "Remainder" && r.remainderFlag == nil {
-			r.remainderFlag = flag
+		if len(tag) != 0 && !flag.isSupported() {
+			fieldError(i, fmt.Errorf("Unsupported flag type: %s", fieldValue.Type()))
+			continue
+		}
+		if flag.isRemainder() {
+			// The remainder of the parent takes precedence. A second
+			// remainder in the same struct is an error.
+			if r.remainderFlag == nil {
+				flag.flagSet = r
+				r.remainderFlag = flag
+			} else if r.remainderFlag.flagSet == r {
+				fieldError(i, fmt.Errorf("Remainder is already defined by %s", structValue.Type().Field(r.remainderFlag.index).Name))
+			}
+			continue
+		}
+		if flag.isPositional() {
+			flag.flagSet = r
+			r.Positionals = append(r.Positionals, flag)
+			continue
 		}
 
 		if len(tag) != 0 {
+			if flag.Short == "" && flag.Long == "" {
+				fieldError(i, fmt.Errorf("Flag has neither a short nor a long name"))
+				continue
+			}
+			flag.flagSet = r
 			r.Flags = append(r.Flags, flag)
 		}
+		if _, ok := flag.optionMeta["decrement"]; ok {
+			dec, err := decrementFlag(flag)
+			if err != nil {
+				fieldError(i, err)
+				continue
+			}
+			dec.flagSet = r
+			dec.index = i
+			r.Flags = append(r.Flags, dec)
+		}
 	}
 
 	// Parse verb fields
 	for i++; i < structValue.Type().NumField(); i++ {
 		once.Do(func() {
 			r.Verbs = make(map[string]*FlagSet)
+			r.verbAliases = make(map[string]string)
 		})
-		fieldValue := structValue.Field(i)
-		tag := structValue.Type().Field(i).Tag.Get("goptions")
-		r.Verbs[tag] = newFlagset(tag, fieldValue, r)
+		field := structValue.Type().Field(i)
+		if field.Type.Kind() != reflect.Struct {
+			fieldError(i, fmt.Errorf("Verb has to be a struct"))
+			continue
+		}
+		verb, verbErrs := newFlagset("", structValue.Field(i), r)
+		verb.index = i
+		for _, err := range verbErrs {
+			if fe, ok := err.(*FieldError); ok {
+				fe.Field = field.Name + "." + fe.Field
+			}
+			errs = append(errs, err)
+		}
+		err := parseVerbTag(verb, field.Tag.Get("goptions"))
+		if err != nil {
+			fieldError(i, err)
+			continue
+		}
+		for _, name := range append([]string{verb.Name}, verb.Aliases...) {
+			if _, ok := r.VerbByName(name); ok {
+				fieldError(i, fmt.Errorf("Verb %s is already defined", name))
+			}
+		}
+		r.Verbs[verb.Name] = verb
+		for _, alias := range verb.Aliases {
+			r.verbAliases[alias] = verb.Name
+		}
 	}
+	errs = append(errs, r.sortPositionals()...)
 	r.createMaps()
-	return r
+	return r, errs
 }
 
 var (
@@ -110,29 +237,134 @@ var (
 )
 
 // Parse takes the command line arguments and sets the corresponding values
-// in the FlagSet's struct.
+// in the FlagSet's struct. Flags which have not been specified on the command
+// line are taken from the environment and then from the configuration file
+// afterwards.
 func (fs *FlagSet) Parse(args []string) (err error) {
+	fs.tokens, fs.errs = nil, nil
+	defer func() {
+		if err == ErrHelpRequest || len(fs.errs) == 0 {
+			return
+		}
+		if err != nil {
+			fs.errs = append(fs.errs, err)
+		}
+		err = fs.errs
+	}()
+	err = fs.parseArgs(args, len(args))
+	if err != nil {
+		return
+	}
+	path := fs.selectedPath()
+	for _, set := range path {
+		err = set.applyEnv()
+		if err != nil {
+			return
+		}
+	}
+	err = fs.loadConfig()
+	if err != nil {
+		return
+	}
+	for _, set := range path {
+		for _, verr := range set.validate() {
+			err = set.recoverError(verr)
+			if err != nil {
+				return
+			}
+		}
+	}
+	return nil
+}
+
+// parseArgs sets the values of the flags given on the command line and
+// selects the verb. Verbs are parsed recursively. total is the number of
+// arguments originally passed to Parse() and is used to record the position
+// of each flag.
+func (fs *FlagSet) parseArgs(args []string, total int) (err error) {
+	fs.selected = nil
 	// Parse global flags
+	terminated := false
+	positional, positions := []string{}, []int{}
+	// cluster is set if args[0] is the rest of a short flag cluster rather
+	// than an argument passed by the caller.
+	cluster := false
 	for len(args) > 0 {
-		if !((isLong(args[0]) && fs.hasLongFlag(args[0][2:])) ||
-			(isShort(args[0]) && fs.hasShortFlag(args[0][1:2]))) {
+		if cluster && strings.HasPrefix(args[0], "--") {
+			// A dash inside a short flag cluster is neither the terminator
+			// nor the start of a long flag.
+			return &UnknownFlagError{
+				ErrorContext: ErrorContext{Verbs: fs.verbPath(), Index: total - len(args)},
+				Name:         "--",
+				Args:         args,
+			}
+		}
+		if args[0] == "--" {
+			// End of options. Everything after it is passed on verbatim.
+			args, terminated = args[1:], true
 			break
 		}
-		f := fs.FlagByName(args[0])
-		args, err = f.Parse(args)
+		var f *Flag
+		var canonical string
+		f, canonical, err = fs.lookupFlag(args[0])
 		if err != nil {
-			return
+			return fs.addContext(err, total-len(args))
+		}
+		// Negative numbers are only exempt if the caller passed them.
+		if f == nil && (cluster || isFlagLike(args[0])) {
+			return fs.unknownFlagError(args, total-len(args))
+		}
+		if f == nil {
+			if verb, err := fs.resolveVerb(args[0]); (verb != nil || err != nil) && len(positional) == 0 {
+				break
+			}
+			if fs.mode() != PermuteMode {
+				break
+			}
+			positional = append(positional, args[0])
+			positions = append(positions, total-len(args))
+			args = args[1:]
+			continue
 		}
-		if f == fs.helpFlag && f.WasSpecified {
+		pos, name := total-len(args), args[0]
+		if isShort(name) {
+			name = name[:2]
+		} else if isLong(name) {
+			name, _, _ = splitLong(name)
+			name = "--" + name
+		}
+		var value string
+		remaining := len(args)
+		args, value, err = f.parse(append([]string{canonical}, args[1:]...))
+		cluster = len(args) == remaining
+		if err != nil {
+			err = fs.recoverError(fs.addContext(err, pos))
+			if err != nil {
+				return
+			}
+		}
+		fs.addToken(Token{Flag: f, Name: name, Value: value, Position: pos})
+		f.Source = Source{Kind: SourceArgs, Position: pos}
+		if f.counter != nil {
+			f.counter.Source = f.Source
+		}
+		if f == f.flagSet.helpFlag && f.WasSpecified {
 			return ErrHelpRequest
 		}
 	}
 
 	// Process verb
-	if len(args) > 0 {
-		if verb, ok := fs.Verbs[args[0]]; ok {
-			fs.verbFlag.value.Set(reflect.ValueOf(Verbs(args[0])))
-			err := verb.Parse(args[1:])
+	if len(args) > 0 && !terminated && len(positional) == 0 {
+		var verb *FlagSet
+		verb, err = fs.resolveVerb(args[0])
+		if err != nil {
+			return fs.addContext(err, total-len(args))
+		}
+		if verb != nil {
+			fs.verbFlag.value.Set(reflect.ValueOf(Verbs(verb.Name)))
+			fs.selected = verb
+			fs.addToken(Token{Verb: verb, Name: args[0], Position: total - len(args)})
+			err := verb.parseArgs(args[1:], total)
 			if err != nil {
 				return err
 			}
@@ -140,46 +372,125 @@ func (fs *FlagSet) Parse(args []string) (err error) {
 		}
 	}
 
+	// Process positional arguments
+	for i := range args {
+		positions = append(positions, total-len(args)+i)
+	}
+	args, positions, err = fs.setPositionals(append(positional, args...), positions)
+	if err != nil {
+		return
+	}
+
 	// Process remainder
 	if len(args) > 0 {
 		if fs.remainderFlag == nil {
-			return fmt.Errorf("Invalid trailing arguments: %v", args)
+			return fs.trailingError(args, positions)
+		}
+		err = fs.remainderFlag.setRemainder(args, positions)
+		if err != nil {
+			return
 		}
-		remainder := reflect.MakeSlice(fs.remainderFlag.value.Type(), len(args), len(args))
-		reflect.Copy(remainder, reflect.ValueOf(args))
-		fs.remainderFlag.value.Set(remainder)
 	}
+	return nil
+}
 
-	// Check for unset, obligatory, single Flags
-	for _, f := range fs.Flags {
+// selectedPath returns the FlagSet and the FlagSets of all verbs selected by
+// the last call to Parse().
+func (fs *FlagSet) selectedPath() []*FlagSet {
+	r := []*FlagSet{}
+	for set := fs; set != nil; set = set.selected {
+		r = append(r, set)
+	}
+	return r
+}
+
+// FullName returns the name of the FlagSet including the names of all
+// parent verbs (e.g. "cluster node add"). For the top-level FlagSet, Name is
+// returned.
+func (fs *FlagSet) FullName() string {
+	if fs.parent == nil {
+		return fs.Name
+	}
+	return strings.Join(fs.verbPath(), " ")
+}
+
+// SelectedVerbs returns the names of the verbs selected by the last call to
+// Parse(), outermost first.
+func (fs *FlagSet) SelectedVerbs() []string {
+	path := fs.selectedPath()
+	return path[len(path)-1].verbPath()[len(fs.verbPath()):]
+}
+
+// verbPath returns the names of the verbs leading to the FlagSet.
+func (fs *FlagSet) verbPath() []string {
+	r := []string{}
+	for set := fs; set.parent != nil; set = set.parent {
+		r = append([]string{set.Name}, r...)
+	}
+	return r
+}
+
+// walk calls fn for the FlagSet and recursively for all its verbs.
+func (fs *FlagSet) walk(fn func(set *FlagSet)) {
+	fn(fs)
+	for _, verb := range fs.Verbs {
+		verb.walk(fn)
+	}
+}
+
+// root returns the top-level FlagSet.
+func (fs *FlagSet) root() *FlagSet {
+	r := fs
+	for r.parent != nil {
+		r = r.parent
+	}
+	return r
+}
+
+// validate checks the obligatory flags and MutexGroups of the FlagSet.
+func (fs *FlagSet) validate() Errors {
+	var errs Errors
+	// Check for unset, obligatory, single Flags and positional arguments
+	for _, f := range append(fs.Flags, fs.Positionals...) {
 		if f.Obligatory && !f.WasSpecified && len(f.MutexGroups) == 0 {
-			return fmt.Errorf("%s must be specified", f.Name())
+			errs = append(errs, &ObligatoryError{ErrorContext{Flag: f, Verbs: fs.verbPath(), Index: -1}})
 		}
 	}
 
 	// Check for multiple set Flags in one mutex group
 	// Check also for unset, obligatory mutex groups
 	mgs := fs.MutexGroups()
-	for _, mg := range mgs {
-		if !mg.IsValid() {
-			return fmt.Errorf("Exactly one of %s must be specified", strings.Join(mg.Names(), ", "))
+	names := make([]string, 0, len(mgs))
+	for name := range mgs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !mgs[name].IsValid() {
+			errs = append(errs, &MutexGroupError{ErrorContext{Verbs: fs.verbPath(), Index: -1}, name, mgs[name]})
 		}
 	}
-	return nil
+	return errs
 }
 
 func (fs *FlagSet) createMaps() {
 	fs.longMap = make(map[string]*Flag)
 	fs.shortMap = make(map[string]*Flag)
 	for _, flag := range fs.Flags {
-		fs.longMap[flag.Long] = flag
-		fs.shortMap[flag.Short] = flag
+		if len(flag.Long) > 0 {
+			fs.longMap[flag.Long] = flag
+		}
+		if flag.Negatable {
+			fs.longMap["no-"+flag.Long] = flag
+		}
+		if len(flag.Short) > 0 {
+			fs.shortMap[flag.Short] = flag
+		}
 	}
 }
 
 func (fs *FlagSet) hasLongFlag(fname string) bool {
-	_, ok := fs.longMap[fname]
-	return ok
+	return len(fs.longFlagCandidates(fname, false)) == 1
 }
 
 func (fs *FlagSet) hasShortFlag(fname string) bool {
@@ -187,15 +498,75 @@ func (fs *FlagSet) hasShortFlag(fname string) bool {
 	return ok
 }
 
+// FlagByName returns the flag handling the given argument. Long flags may
+// carry an attached value (`--name=value`), short flags may be part of a
+// cluster (`-nvalue`). If the top-level FlagSet allows Abbreviations, long
+// flags can be abbreviated. If no flag matches, nil is returned.
 func (fs *FlagSet) FlagByName(fname string) *Flag {
 	if isShort(fname) && fs.hasShortFlag(fname[1:2]) {
 		return fs.shortMap[fname[1:2]]
-	} else if isLong(fname) && fs.hasLongFlag(fname[2:]) {
-		return fs.longMap[fname[2:]]
+	} else if isLong(fname) {
+		if name, _, _ := splitLong(fname); len(name) > 0 && fs.hasLongFlag(name) {
+			return fs.longMap[fs.longFlagCandidates(name, false)[0]]
+		}
 	}
 	return nil
 }
 
+// lookupFlag returns the flag handling the given argument. Besides the
+// FlagSet's own flags, the persistent flags of all parent FlagSets are
+// considered. If the flag has been abbreviated, the argument is returned
+// with the full name of the flag. Otherwise it is returned unchanged.
+func (fs *FlagSet) lookupFlag(arg string) (*Flag, string, error) {
+	// Exact matches take priority over abbreviations.
+	if f := fs.exactFlag(arg); f != nil {
+		return f, arg, nil
+	}
+	for set := fs.parent; set != nil; set = set.parent {
+		if f := set.exactFlag(arg); f != nil && f.Persistent {
+			return f, arg, nil
+		}
+	}
+	if !isLong(arg) || !fs.root().Abbreviations {
+		return nil, arg, nil
+	}
+	name, value, hasValue := splitLong(arg)
+	matches := make(map[string]*Flag)
+	for set := fs; set != nil; set = set.parent {
+		for _, key := range set.longFlagCandidates(name, set != fs) {
+			if _, ok := matches[key]; !ok {
+				matches[key] = set.longMap[key]
+			}
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, arg, nil
+	case 1:
+		for key, f := range matches {
+			arg = "--" + key
+			if hasValue {
+				arg += "=" + value
+			}
+			return f, arg, nil
+		}
+	}
+	candidates := make([]string, 0, len(matches))
+	for key := range matches {
+		candidates = append(candidates, "--"+key)
+	}
+	sort.Strings(candidates)
+	return nil, arg, &AmbiguousError{ErrorContext{Verbs: fs.verbPath(), Index: -1}, "--" + name, candidates}
+}
+
+// VerbByName returns the FlagSet of the verb with the given name or alias.
+// If the top-level FlagSet allows Abbreviations, a unique prefix of a verb's
+// name or alias is accepted as well.
+func (fs *FlagSet) VerbByName(name string) (*FlagSet, bool) {
+	verb, err := fs.resolveVerb(name)
+	return verb, verb != nil && err == nil
+}
+
 // MutexGroups returns a map of Flag lists which contain mutually
 // exclusive flags.
 func (fs *FlagSet) MutexGroups() map[string]MutexGroup {
@@ -223,9 +594,14 @@ func (fs *FlagSet) ParseAndFail(w io.Writer, args []string) {
 	err := fs.Parse(args)
 	if err != nil {
 		errCode := 0
-		if err != ErrHelpRequest {
+		if errs, ok := err.(Errors); ok {
+			errCode = 1
+			for _, err := range errs {
+				fmt.Fprintf(w, "Error: %s\n", errorMessage(err))
+			}
+		} else if err != ErrHelpRequest {
 			errCode = 1
-			fmt.Fprintf(w, "Error: %s\n", err)
+			fmt.Fprintf(w, "Error: %s\n", errorMessage(err))
 		}
 		fs.PrintHelp(w)
 		os.Exit(errCode)
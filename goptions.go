@@ -11,9 +11,27 @@ by the tags in a (possibly anonymous) struct.
     	Verbosity int `goptions:"-v, --verbose"`
     }
 
-Short flags can be combined (e.g. `-nfv`). Long flags take their value after a
-separating space. The equals notation (`--long-flag=value`) is NOT supported
-right now.
+Short flags can be combined (e.g. `-fv`). Long flags take their value either
+after a separating space or attached with an equals sign (`--name=value`).
+Short flags take their value after a separating space or attached directly
+(`-nvalue`). A flag expecting a value at the end of a cluster consumes the
+rest of the cluster (`-fvnvalue`). The argument `--` terminates option parsing.
+All arguments after it are put into the Remainder verbatim, even if they look
+like flags or verbs.
+
+By default, flag parsing stops at the first argument which is neither a flag
+nor a verb. Setting a FlagSet's Mode to PermuteMode allows flags and other
+arguments to be interspersed (e.g. `file1 -v file2`).
+
+Arguments which look like flags but are not defined are rejected with an
+*UnknownFlagError, as are misspelled verbs with an *UnknownVerbError. Both
+suggest similar names (`Unknown flag --tiemout, did you mean --timeout?`).
+Negative numbers and arguments after `--` are not considered flags.
+
+If FlagSet.Abbreviations is set, long flags and verbs can be abbreviated to
+any unambiguous prefix (`--verb` for `--verbose`). Exact matches always take
+priority. An ambiguous prefix is rejected with an *AmbiguousError listing
+the candidates.
 
 Every member of the struct which is supposed to catch a command line value
 has to have a "goptions" tag. The contains the short and long flag names for this
@@ -28,9 +46,45 @@ member but can additionally specify any of these options below.
                         will be returned when Parse() is called. If one flag in a
                         MutexGroup is `obligatory` one flag of the group must be
                         specified. A flag can be in multiple MutexGroups at once.
+    env='...'         - Name of the environment variable the flag's value is
+                        taken from if the flag has not been specified on the
+                        command line. A value taken from the environment
+                        satisfies `obligatory`. If a FlagSet has an EnvPrefix,
+                        the name is derived automatically for all flags
+                        (PREFIX_VERB_LONG_NAME).
+    persistent        - The flag is also accepted after a verb (at any depth).
+                        Its value is still stored in the struct of the flag's
+                        FlagSet.
+    secret            - The flag's value is redacted by FlagSet.Explain() and
+                        its default value is not shown in the help.
+    optional='...'    - The flag's value is optional and only taken if it is
+                        attached to the flag (`--color=always`, `-calways`).
+                        If the flag is given without a value, the option's
+                        value is used instead (`--color` means `auto` with
+                        `optional='auto'`).
+    placeholder='...' - Name of the flag's value in the help. Defaults to the
+                        upper-cased long name.
 
 Depending on the type of the struct member, additional options might become available:
 
+    Type: bool
+        The flag does not take a separate value. An attached value
+        (`--flag=false`) can be any of true, false, yes, no, 1 and 0.
+    Available options:
+        negatable - Additionally register `--no-<long name>`, which sets
+                    the member to false. The flag may then be given
+                    repeatedly; the last occurrence wins
+                    (`--color --no-color`).
+
+    Type: int, int32, int64
+    Available options:
+        count             - The flag does not take a value. Instead, every
+                            occurrence increments the member by one. Works
+                            inside short flag clusters (e.g. `-vvv`).
+        decrement='...'   - Register an additional flag with the given
+                            names (e.g. `decrement='-q, --quiet'`), which
+                            decrements the member. Needs `count`.
+
     Type: *os.File
         The given string is interpreted as a path to a file. If the string is "-"
         os.Stdin or os.Stdout will be used. os.Stdin will be returned, if the
@@ -57,9 +111,108 @@ specification the underlying type will be used.
         Servers []string `goptions:"-s, --server, description='Servers to connect to'"`
     }{}
 
+The first value given for a slice replaces its default value. With the
+`append` option, values are added to the default instead. The same applies
+to maps.
+
+With the `sep` option, a single occurrence can hold multiple values
+(`--server a,b,c` with `sep=','`). An element containing the separator can
+be enclosed in quotes (`"a,b",c`) or the separator can be escaped with a
+backslash (`a\,b,c`). The option applies to values from the environment and
+configuration files as well, where it replaces the FlagSet's EnvSeparator.
+
+If a member is a map with string keys, every occurrence of the flag adds a
+`key=value` pair. The value is converted to the map's value type.
+
+    var options struct {
+        Labels map[string]string `goptions:"-l, --label, sep=','"`
+    }{}
+
+Maps have these additional options:
+
+    kvsep='...'       - Separator between key and value. Defaults to `=`.
+    sep='...'         - Separator between multiple pairs in one occurrence
+                        (`--label env=prod,team=core`), as for slices.
+    duplicates='...'  - Either `last` (default), where the last value of
+                        a key wins, or `error`.
+
+Values for flags which have not been specified on the command line can be
+taken from the environment (see the `env` option and FlagSet.EnvPrefix) and
+from a configuration file (see ConfigFile and FlagSet.ConfigPath). The command
+line takes precedence over the environment, which takes precedence over the
+configuration file. Keys in the configuration file are long flag names,
+sections correspond to verbs.
+
+    ; INI
+    timeout = 10s
+    server = a
+    server = b
+    [delete]
+    force = true
+
+    // JSON
+    {"timeout": "10s", "server": ["a", "b"], "delete": {"force": true}}
+
+    # dotenv, keys are environment variable names or long flag names
+    MYTOOL_TIMEOUT=10s
+
+Positional arguments are members whose tag has a `pos` option instead of flag
+names. They are filled in order of their position from the arguments which are
+neither flags nor verbs. A slice can be used as the last positional argument
+to take all remaining arguments. Arguments not taken by any positional
+argument end up in the Remainder.
+
+    var options struct {
+        Source *os.File `goptions:"pos=1, name='SOURCE', obligatory, rdonly"`
+        Dests  []string `goptions:"pos=2, name='DEST', min=1"`
+    }
+
+    pos=n             - Position of the argument, starting at 1.
+    name='...'        - Name of the argument in the help.
+    min=n, max=n      - Minimum and maximum number of arguments taken by a
+                        slice. A minimum greater than 0 implies `obligatory`.
+
+The Remainder takes the arguments verbatim. To have them converted, any slice
+of a supported type can be marked with the `remainder` option instead. The
+index of an argument which cannot be converted is reported by Parse().
+
+    var options struct {
+        Ports []int `goptions:"remainder"`
+    }
+
 goptions also has support for verbs. Each verb accepts its own set of flags which
 take exactly the same tag format as global options. For an usage example of verbs
-see the PrintHelp() example.
+see the PrintHelp() example. The tag of a verb field starts with the verb's name,
+optionally followed by any of these options:
+
+    description='...' - Set the description of the verb. Will be used by the
+                        HelpFunc.
+    alias='...'       - Comma-separated list of alternative names for the
+                        verb. The Verbs field is always set to the
+                        verb's name.
+    hidden            - Do not show the verb in the help.
+
+A verb's struct can have verbs itself, which allows
+arbitrarily nested verbs (e.g. `tool cluster node add`).
+
+Instead of switching on the selected verb after parsing, verb structs can
+implement Command. FlagSet.Execute() (or ParseAndRun()) parses the arguments
+and runs the Command of the selected verb. Structs of all selected levels can
+implement BeforeHook and AfterHook to run code around it.
+
+A FlagSet keeps track of the flags which have been specified. To parse
+arguments again, FlagSet.Reset() restores all default values first.
+FlagSet.Bind() reuses a FlagSet for another instance of the same struct.
+
+Errors returned by Parse() can be inspected with errors.As(). They have types
+like *UnknownFlagError, *MissingValueError, *ObligatoryError or
+*ConversionError, which carry an ErrorContext with the offending Flag, the
+selected verbs and the index of the argument. If FlagSet.ReportAllErrors is
+set, Parse() continues after invalid values, missing obligatory flags and
+violated MutexGroups and returns all of them as Errors.
+
+Parse() never modifies the given arguments. FlagSet.Tokens() returns how
+each argument has been interpreted (flag, value and position).
 */
 package goptions
 
@@ -84,6 +237,13 @@ func ParseAndFail(v interface{}) {
 	globalFlagSet.ParseAndFail(os.Stderr, os.Args[1:])
 }
 
+// ParseAndRun is a convenience function to parse os.Args[1:] like
+// ParseAndFail() and run the selected Command.
+func ParseAndRun(v interface{}) {
+	globalFlagSet = NewFlagSet(filepath.Base(os.Args[0]), v)
+	globalFlagSet.ParseAndRun(os.Stderr, os.Args[1:])
+}
+
 // Parse parses the command-line flags from os.Args[1:].
 func Parse(v interface{}) error {
 	globalFlagSet = NewFlagSet(filepath.Base(os.Args[0]), v)
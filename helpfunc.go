@@ -2,23 +2,31 @@ package goptions
 
 import (
 	"io"
+	"strings"
 	"sync"
 	"text/tabwriter"
 	"text/template"
 )
 
+var (
+	// Functions available in help templates.
+	helpFuncs = template.FuncMap{
+		"join": strings.Join,
+	}
+)
+
 // HelpFunc is the signature of a function responsible for printing the help.
 type HelpFunc func(w io.Writer, fs *FlagSet)
 
 // Generates a new HelpFunc taking a `text/template.Template`-formatted
 // string as an argument. The resulting template will be executed with the FlagSet
-// as its data.
+// as its data. The function `join` (strings.Join) is available in the template.
 func NewTemplatedHelpFunc(tpl string) HelpFunc {
 	var once sync.Once
 	var t *template.Template
 	return func(w io.Writer, fs *FlagSet) {
 		once.Do(func() {
-			t = template.Must(template.New("helpTemplate").Parse(tpl))
+			t = template.Must(template.New("helpTemplate").Funcs(helpFuncs).Parse(tpl))
 		})
 		err := t.Execute(w, fs)
 		if err != nil {
@@ -28,37 +36,59 @@ func NewTemplatedHelpFunc(tpl string) HelpFunc {
 }
 
 const (
-	_DEFAULT_HELP = "\xffUsage: {{.Name}} [global options] {{with .Verbs}}<verb> [verb options]{{end}}\n" +
-		"\n" +
-		"Global options:\xff" +
+	_DEFAULT_HELP_FLAGS = "{{define \"flags\"}}" +
 		"{{range .Flags}}" +
 		"\n\t" +
-		"\t{{with .Short}}" + "-{{.}}," + "{{end}}" +
-		"\t{{with .Long}}" + "--{{.}}" + "{{end}}" +
+		"\t{{if .Short}}" + "-{{.Short}}" +
+		"{{if and .IsOptional (not .Long)}}[{{.Placeholder}}]{{end}}" + "," + "{{end}}" +
+		"\t{{if .Long}}" + "--{{if .Negatable}}[no-]{{end}}{{.Long}}" +
+		"{{if .IsOptional}}[={{.Placeholder}}]{{end}}" + "{{end}}" +
+		"{{if .IsMap}} {{.Placeholder}}{{end}}" +
 		"\t{{.Description}}" +
-		"{{with .DefaultValue}}" +
+		"{{if not .IsSecret}}{{with .DefaultValue}}" +
 		" (default: {{.}})" +
+		"{{end}}{{end}}" +
+		"{{with .EnvVar}}" +
+		" [${{.}}]" +
 		"{{end}}" +
 		"{{if .Obligatory}}" +
 		" (*)" +
 		"{{end}}" +
 		"{{end}}" +
-		"\xff\n\n{{with .Verbs}}Verbs:\xff" +
-		"{{range .}}" +
-		"\xff\n    {{.Name}}:\xff" +
-		"{{range .Flags}}" +
-		"\n\t" +
-		"\t{{with .Short}}" + "-{{.}}," + "{{end}}" +
-		"\t{{with .Long}}" + "--{{.}}" + "{{end}}" +
+		"{{range .Positionals}}" +
+		"\n\t\t" +
+		"\t{{.Placeholder}}" +
 		"\t{{.Description}}" +
-		"{{with .DefaultValue}}" +
-		" (default: {{.}})" +
-		"{{end}}" +
 		"{{if .Obligatory}}" +
 		" (*)" +
 		"{{end}}" +
 		"{{end}}" +
+		"{{end}}"
+	_DEFAULT_HELP_POSITIONALS = "{{define \"positionals\"}}" +
+		"{{range .Positionals}}" +
+		" {{if not .Obligatory}}[{{end}}" +
+		"{{.Placeholder}}{{if .IsMulti}}...{{end}}" +
+		"{{if not .Obligatory}}]{{end}}" +
+		"{{end}}" +
+		"{{end}}"
+	_DEFAULT_HELP_VERBS = "{{define \"verbs\"}}" +
+		"{{range .Verbs}}" +
+		"{{if not .Hidden}}" +
+		"\xff\n    {{.FullName}}{{template \"positionals\" .}}:" +
+		"{{with .Description}} {{.}}{{end}}" +
+		"{{with .Aliases}} (aliases: {{join . \", \"}}){{end}}\xff" +
+		"{{template \"flags\" .}}" +
+		"{{template \"verbs\" .}}" +
+		"{{end}}" +
 		"{{end}}" +
+		"{{end}}"
+	_DEFAULT_HELP = _DEFAULT_HELP_FLAGS + _DEFAULT_HELP_POSITIONALS + _DEFAULT_HELP_VERBS +
+		"\xffUsage: {{.Name}} [global options]{{template \"positionals\" .}}{{with .Verbs}} <verb> [verb options]{{end}}\n" +
+		"\n" +
+		"Global options:\xff" +
+		"{{template \"flags\" .}}" +
+		"\xff\n\n{{with .Verbs}}Verbs:\xff" +
+		"{{template \"verbs\" $}}" +
 		"{{end}}" +
 		"\n"
 )
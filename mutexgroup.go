@@ -46,3 +46,20 @@ func (mg MutexGroup) Names() []string {
 	}
 	return r
 }
+
+// shadowedFlags returns the set of unspecified flags which share a
+// MutexGroup with a specified flag.
+func (fs *FlagSet) shadowedFlags() map[*Flag]bool {
+	r := make(map[*Flag]bool)
+	for _, mg := range fs.MutexGroups() {
+		if !mg.WasSpecified() {
+			continue
+		}
+		for _, flag := range mg {
+			if !flag.WasSpecified {
+				r[flag] = true
+			}
+		}
+	}
+	return r
+}
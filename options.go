@@ -19,6 +19,31 @@ var (
 			"description": description,
 			"obligatory":  obligatory,
 			"mutexgroup":  mutexgroup,
+			"optional":    optional,
+			"env":         env,
+			"secret":      secret,
+			"persistent":  persistent,
+			"remainder":   remainder,
+			"pos":         pos,
+			"name":        pos_name,
+			"min":         pos_count,
+			"max":         pos_count,
+			"placeholder": placeholder,
+		},
+		reflect.TypeOf(new(bool)).Elem(): optionMap{
+			"negatable": negatable,
+		},
+		reflect.TypeOf(new(int)).Elem(): optionMap{
+			"count":     count,
+			"decrement": decrement,
+		},
+		reflect.TypeOf(new(int32)).Elem(): optionMap{
+			"count":     count,
+			"decrement": decrement,
+		},
+		reflect.TypeOf(new(int64)).Elem(): optionMap{
+			"count":     count,
+			"decrement": decrement,
 		},
 		reflect.TypeOf(new(time.Time)).Elem(): optionMap{
 			"format": time_format,
@@ -37,6 +62,32 @@ var (
 	}
 )
 
+var (
+	// Options available for all slice and map types
+	kindOptionMap = map[reflect.Kind]optionMap{
+		reflect.Slice: optionMap{
+			"sep":    sep,
+			"append": appendValues,
+		},
+		reflect.Map: optionMap{
+			"append":     appendValues,
+			"kvsep":      kvsep,
+			"sep":        sep,
+			"duplicates": duplicates,
+		},
+	}
+)
+
+type verbOptionFunc func(fs *FlagSet, option, value string) error
+
+var (
+	verbOptionMap = map[string]verbOptionFunc{
+		"description": verb_description,
+		"alias":       verb_alias,
+		"hidden":      verb_hidden,
+	}
+)
+
 // Wraps another optionFunc and inits optionMeta[field] with value if it does
 // not have one already.
 func initOptionMeta(fn optionFunc, field string, init_value interface{}) optionFunc {
@@ -68,6 +119,139 @@ func mutexgroup(f *Flag, option, value string) error {
 	return nil
 }
 
+func env(f *Flag, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Env option needs a value")
+	}
+	f.optionMeta["env"] = value
+	return nil
+}
+
+func remainder(f *Flag, option, value string) error {
+	f.optionMeta["remainder"] = true
+	return nil
+}
+
+func pos(f *Flag, option, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return fmt.Errorf("Option needs a positive number")
+	}
+	f.optionMeta["pos"] = n
+	return nil
+}
+
+func pos_name(f *Flag, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Name option needs a value")
+	}
+	f.optionMeta["placeholder"] = value
+	f.optionMeta["name"] = value
+	return nil
+}
+
+func pos_count(f *Flag, option, value string) error {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 0 {
+		return fmt.Errorf("Option needs a non-negative number")
+	}
+	f.optionMeta[option] = n
+	if option == "min" && n > 0 {
+		f.Obligatory = true
+	}
+	return nil
+}
+
+func persistent(f *Flag, option, value string) error {
+	f.Persistent = true
+	return nil
+}
+
+func secret(f *Flag, option, value string) error {
+	f.optionMeta["secret"] = true
+	return nil
+}
+
+func optional(f *Flag, option, value string) error {
+	f.optionMeta["optional"] = value
+	return nil
+}
+
+func placeholder(f *Flag, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Placeholder option needs a value")
+	}
+	f.optionMeta["placeholder"] = value
+	return nil
+}
+
+func negatable(f *Flag, option, value string) error {
+	f.Negatable = true
+	return nil
+}
+
+func count(f *Flag, option, value string) error {
+	f.optionMeta["count"] = 1
+	return nil
+}
+
+func decrement(f *Flag, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Decrement option needs a value")
+	}
+	f.optionMeta["decrement"] = value
+	return nil
+}
+
+func kvsep(f *Flag, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Kvsep option needs a value")
+	}
+	f.optionMeta["kvsep"] = value
+	return nil
+}
+
+func sep(f *Flag, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Sep option needs a value")
+	}
+	f.optionMeta["sep"] = value
+	return nil
+}
+
+func appendValues(f *Flag, option, value string) error {
+	f.optionMeta["append"] = true
+	return nil
+}
+
+func duplicates(f *Flag, option, value string) error {
+	if value != "error" && value != "last" {
+		return fmt.Errorf("Duplicates option has to be error or last")
+	}
+	f.optionMeta["duplicates"] = value
+	return nil
+}
+
+func verb_description(fs *FlagSet, option, value string) error {
+	fs.Description = strings.Replace(value, `\`, ``, -1)
+	return nil
+}
+
+func verb_alias(fs *FlagSet, option, value string) error {
+	if len(value) <= 0 {
+		return fmt.Errorf("Alias option needs a value")
+	}
+	for _, alias := range strings.Split(value, ",") {
+		fs.Aliases = append(fs.Aliases, strings.TrimSpace(alias))
+	}
+	return nil
+}
+
+func verb_hidden(fs *FlagSet, option, value string) error {
+	fs.Hidden = true
+	return nil
+}
+
 func file_create(f *Flag, option, value string) error {
 	f.optionMeta["file_mode"] = f.optionMeta["file_mode"].(int) | os.O_CREATE
 	return nil
@@ -132,5 +316,23 @@ func optionMapForType(t reflect.Type) optionMap {
 	for k, v := range m {
 		r[k] = v
 	}
+	for k, v := range kindOptionMap[t.Kind()] {
+		r[k] = v
+	}
 	return r
 }
+
+// isKnownOption returns true if the option is available for any type.
+func isKnownOption(option string) bool {
+	for _, m := range typeOptionMap {
+		if _, ok := m[option]; ok {
+			return true
+		}
+	}
+	for _, m := range kindOptionMap {
+		if _, ok := m[option]; ok {
+			return true
+		}
+	}
+	return false
+}
@@ -2,7 +2,11 @@ package goptions
 
 import (
 	"fmt"
+	"net/url"
+	"os"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestParse_StringValue(t *testing.T) {
@@ -245,6 +249,142 @@ func TestParse_VerbRemainder(t *testing.T) {
 	}
 }
 
+func TestParse_TypedRemainder(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Limit int   `goptions:"-l"`
+		Ports []int `goptions:"remainder"`
+	}
+
+	args = []string{"-l", "123", "80", "443"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Limit == 123 &&
+		reflect.DeepEqual(options.Ports, []int{80, 443})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"-l", "123", "80", "https"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+	expected := "Invalid argument 3 (https): strconv.ParseInt: parsing \"https\": invalid syntax"
+	if err.Error() != expected {
+		t.Fatalf("Unexpected error: %s", err)
+	}
+}
+
+func TestParse_TypedVerbRemainder(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbs
+		Open struct {
+			URLs []*url.URL `goptions:"remainder"`
+		} `goptions:"open"`
+	}
+
+	args = []string{"open", "http://a", "http://b"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(len(options.Open.URLs) == 2 &&
+		options.Open.URLs[0].Host == "a" &&
+		options.Open.URLs[1].Host == "b") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_Map(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Labels  map[string]string `goptions:"-l, --label"`
+		Weights map[string]int    `goptions:"-w, --weight, sep=',', kvsep=':'"`
+	}
+
+	args = []string{"-l", "env=prod", "--label=team=core", "-l", "env=dev", "-w", "a:1,b:2"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(reflect.DeepEqual(options.Labels, map[string]string{"env": "dev", "team": "core"}) &&
+		reflect.DeepEqual(options.Weights, map[string]int{"a": 1, "b": 2})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"-w", "a=1"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil || err.Error() != `Invalid pair "a=1", expected KEY:VALUE` {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestParse_MapDuplicates(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Labels map[string]string `goptions:"-l, --label, duplicates=error"`
+	}
+
+	args = []string{"-l", "env=prod", "-l", "env=dev"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil || err.Error() != "Duplicate key env" {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func TestParse_SliceDefault(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	defaultLabels := map[string]string{"env": "dev"}
+	options := struct {
+		Servers []string          `goptions:"-s, --server"`
+		Ports   []int             `goptions:"-p, --port, append"`
+		Labels  map[string]string `goptions:"-l, --label, append"`
+		Tags    map[string]string `goptions:"-t, --tag"`
+	}{
+		Servers: []string{"localhost"},
+		Ports:   []int{80},
+		Labels:  defaultLabels,
+		Tags:    map[string]string{"a": "b"},
+	}
+
+	args = []string{"-s", "prod", "-s", "test", "-p", "443", "-l", "team=core", "-t", "c=d"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(reflect.DeepEqual(options.Servers, []string{"prod", "test"}) &&
+		reflect.DeepEqual(options.Ports, []int{80, 443}) &&
+		reflect.DeepEqual(options.Labels, map[string]string{"env": "dev", "team": "core"}) &&
+		reflect.DeepEqual(options.Tags, map[string]string{"c": "d"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+	if !(reflect.DeepEqual(fs.Flags[0].DefaultValue, []string{"localhost"}) &&
+		reflect.DeepEqual(fs.Flags[2].DefaultValue, map[string]string{"env": "dev"}) &&
+		len(defaultLabels) == 1) {
+		t.Fatalf("Default value changed: %#v", fs.Flags)
+	}
+}
+
 func TestParse_NoRemainder(t *testing.T) {
 	var args []string
 	var err error
@@ -450,3 +590,681 @@ func TestParse_DashAsRemainder(t *testing.T) {
 	}
 
 }
+
+func TestParse_LongEqualsValue(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Name  string `goptions:"--name"`
+		Empty string `goptions:"--empty"`
+	}
+
+	args = []string{"--name=Some=Name", "--empty="}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "Some=Name" &&
+		options.Empty == "" &&
+		fs.FlagByName("--empty").WasSpecified) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_LongEqualsValueOnBool(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Help Help `goptions:"--help"`
+	}
+
+	args = []string{"--help=yes"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil || err == ErrHelpRequest {
+		t.Fatalf("Parsing should have failed, got: %v", err)
+	}
+}
+
+func TestParse_ShortAttachedValue(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Name   string `goptions:"-n"`
+		Output string `goptions:"-o"`
+	}
+
+	args = []string{"-nSomeName", "-o-"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Name == "SomeName" &&
+		options.Output == "-") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_FlagClusterWithValue(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Fast    bool   `goptions:"-f"`
+		Verbose bool   `goptions:"-v"`
+		Name    string `goptions:"-n"`
+	}
+
+	args = []string{"-fnvalue"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Fast &&
+		!options.Verbose &&
+		options.Name == "value") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	options.Fast, options.Name = false, ""
+	args = []string{"-fvn", "value"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Fast &&
+		options.Verbose &&
+		options.Name == "value") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_Terminator(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Fast bool `goptions:"-f"`
+		Remainder
+
+		Verbs
+		Run struct {
+			Name string `goptions:"-n"`
+		} `goptions:"run"`
+	}
+
+	args = []string{"-f", "--", "-f", "run", "--"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Fast &&
+		options.Verbs == "" &&
+		reflect.DeepEqual(options.Remainder, Remainder{"-f", "run", "--"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	for _, arg := range []string{"-f-", "-f-f"} {
+		fs = NewFlagSet("goptions", &options)
+		err = fs.Parse([]string{arg, "run"})
+		if unknown, ok := err.(*UnknownFlagError); !ok || unknown.Index != 0 || err.Error() != "Unknown flag --" {
+			t.Fatalf("Unexpected error for %s: %#v", arg, err)
+		}
+	}
+}
+
+func TestParse_VerbTerminator(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbs
+		Run struct {
+			Name string `goptions:"-n"`
+			Remainder
+		} `goptions:"run"`
+	}
+
+	args = []string{"run", "-n", "x", "--", "--weird-arg", "-n", "run"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbs == "run" &&
+		options.Run.Name == "x" &&
+		reflect.DeepEqual(options.Run.Remainder, Remainder{"--weird-arg", "-n", "run"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"--", "run"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParse_InheritedRemainderTerminator(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Remainder
+
+		Verbs
+		Run struct {
+			Fast bool `goptions:"-f"`
+		} `goptions:"run"`
+	}
+
+	args = []string{"run", "--", "-f"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbs == "run" &&
+		!options.Run.Fast &&
+		reflect.DeepEqual(options.Remainder, Remainder{"-f"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_PermuteMode(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose bool   `goptions:"-v"`
+		Name    string `goptions:"-n"`
+		Remainder
+	}
+
+	args = []string{"file1", "-v", "file2", "-n", "x", "-", "file3"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbose &&
+		options.Name == "x" &&
+		reflect.DeepEqual(options.Remainder, Remainder{"file1", "file2", "-", "file3"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_PosixMode(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose bool `goptions:"-v"`
+		Remainder
+	}
+
+	args = []string{"file1", "-v", "file2"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PosixMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(!options.Verbose &&
+		reflect.DeepEqual(options.Remainder, Remainder{"file1", "-v", "file2"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_PosixlyCorrect(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose bool `goptions:"-v"`
+		Remainder
+	}
+
+	os.Setenv("POSIXLY_CORRECT", "1")
+	defer os.Unsetenv("POSIXLY_CORRECT")
+	args = []string{"file1", "-v"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(!options.Verbose &&
+		reflect.DeepEqual(options.Remainder, Remainder{"file1", "-v"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_VerbPermuteMode(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Remainder
+
+		Verbs
+		Copy struct {
+			Force bool `goptions:"-f"`
+		} `goptions:"copy"`
+		Move struct {
+			Force bool `goptions:"-f"`
+		} `goptions:"move"`
+	}
+
+	args = []string{"copy", "a", "-f", "b"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbs == "copy" &&
+		options.Copy.Force &&
+		reflect.DeepEqual(options.Remainder, Remainder{"a", "b"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	options.Remainder = nil
+	args = []string{"move", "a", "-f", "b"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	fs.Verbs["move"].Mode = PosixMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbs == "move" &&
+		!options.Move.Force &&
+		reflect.DeepEqual(options.Remainder, Remainder{"a", "-f", "b"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_Negatable(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Color bool `goptions:"-c, --color, negatable"`
+		Cache bool `goptions:"--cache, negatable"`
+	}
+
+	options.Color, options.Cache = true, true
+	args = []string{"--no-color", "--no-cache"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Color || options.Cache {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"-c", "--no-cache"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !options.Color || options.Cache {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"--no-color", "--color", "--cache=false", "--no-cache", "--cache"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !options.Color || !options.Cache {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"--color", "--no-color"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Color {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"--no-color=true"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParse_BoolValue(t *testing.T) {
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Force bool `goptions:"-f, --force"`
+	}
+
+	for value, expected := range map[string]bool{
+		"true": true, "yes": true, "1": true, "TRUE": true,
+		"false": false, "no": false, "0": false, "No": false,
+	} {
+		options.Force = !expected
+		fs = NewFlagSet("goptions", &options)
+		err = fs.Parse([]string{"--force=" + value})
+		if err != nil {
+			t.Fatalf("Parsing failed for %s: %s", value, err)
+		}
+		if options.Force != expected {
+			t.Fatalf("Unexpected value for %s: %#v", value, options)
+		}
+	}
+
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse([]string{"--force=maybe"})
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParse_Counter(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Fast      bool  `goptions:"-f"`
+		Verbosity int   `goptions:"-v, --verbose, count, decrement='-q, --quiet'"`
+		Level     int64 `goptions:"-l, count"`
+	}
+
+	args = []string{"-vvfv", "--verbose", "-q", "-ll"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Fast &&
+		options.Verbosity == 3 &&
+		options.Level == 2) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	options.Verbosity = 1
+	args = []string{"-qq", "--quiet"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Verbosity != -2 {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"--verbose=3"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParse_OptionalValue(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Color string `goptions:"-c, --color, optional='auto'"`
+		Remainder
+	}
+
+	args = []string{"--color", "always"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Color == "auto" &&
+		reflect.DeepEqual(options.Remainder, Remainder{"always"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	options.Remainder = nil
+	for _, arg := range []string{"--color=always", "-calways"} {
+		fs = NewFlagSet("goptions", &options)
+		err = fs.Parse([]string{arg})
+		if err != nil {
+			t.Fatalf("Parsing failed: %s", err)
+		}
+		if options.Color != "always" {
+			t.Fatalf("Unexpected value for %s: %#v", arg, options)
+		}
+	}
+
+	args = []string{"-c"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Color != "auto" {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_NestedVerbs(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose bool `goptions:"-v"`
+		Remainder
+
+		Verbs
+		Cluster struct {
+			Name string `goptions:"-n"`
+
+			Verbs
+			Node struct {
+				Verbs
+				Add struct {
+					Force bool `goptions:"-f"`
+				} `goptions:"add"`
+				Remove struct{} `goptions:"remove"`
+			} `goptions:"node"`
+		} `goptions:"cluster"`
+	}
+
+	args = []string{"-v", "cluster", "-n", "c1", "node", "add", "-f", "node1", "node2"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbose &&
+		options.Verbs == "cluster" &&
+		options.Cluster.Name == "c1" &&
+		options.Cluster.Verbs == "node" &&
+		options.Cluster.Node.Verbs == "add" &&
+		options.Cluster.Node.Add.Force &&
+		reflect.DeepEqual(options.Remainder, Remainder{"node1", "node2"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+	if verbs := fs.SelectedVerbs(); !reflect.DeepEqual(verbs, []string{"cluster", "node", "add"}) {
+		t.Fatalf("Unexpected selected verbs: %#v", verbs)
+	}
+	if verbs := fs.Verbs["cluster"].SelectedVerbs(); !reflect.DeepEqual(verbs, []string{"node", "add"}) {
+		t.Fatalf("Unexpected selected verbs: %#v", verbs)
+	}
+}
+
+func TestParse_VerbAliases(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbs
+		Delete struct {
+			Force bool `goptions:"-f"`
+		} `goptions:"delete, alias='rm, del', description='Delete an entity'"`
+		Debug struct{} `goptions:"debug, hidden"`
+	}
+
+	args = []string{"rm", "-f"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbs == "delete" &&
+		options.Delete.Force) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"debug"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if options.Verbs != "debug" {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestParse_PersistentFlags(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose bool   `goptions:"-v, --verbose, persistent"`
+		Server  string `goptions:"-s, --server, persistent, obligatory"`
+		Quiet   bool   `goptions:"-q, persistent, mutexgroup='output'"`
+		Loud    bool   `goptions:"-l, mutexgroup='output'"`
+
+		Verbs
+		Cluster struct {
+			Verbs
+			Delete struct {
+				Name string `goptions:"-n, --name"`
+			} `goptions:"delete"`
+		} `goptions:"cluster"`
+	}
+
+	args = []string{"cluster", "delete", "-n", "x", "--verbose", "-s", "127.0.0.1"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Verbose &&
+		options.Server == "127.0.0.1" &&
+		options.Cluster.Delete.Name == "x") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	args = []string{"cluster", "delete", "-n", "x"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+
+	args = []string{"-l", "cluster", "-s", "127.0.0.1", "-q"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+
+	args = []string{"-s", "127.0.0.1", "cluster", "-l"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParse_Positionals(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Force   bool          `goptions:"-f"`
+		Source  *url.URL      `goptions:"pos=1, name='SOURCE', obligatory"`
+		Timeout time.Duration `goptions:"pos=2"`
+		Dests   []int         `goptions:"pos=3, name='DEST', min=1, max=2"`
+		Remainder
+	}
+
+	args = []string{"-f", "http://www.google.com", "3s", "1", "2", "3"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Force &&
+		options.Source.Host == "www.google.com" &&
+		options.Timeout == 3*time.Second &&
+		reflect.DeepEqual(options.Dests, []int{1, 2}) &&
+		reflect.DeepEqual(options.Remainder, Remainder{"3"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+
+	for _, args = range [][]string{
+		{},
+		{"http://www.google.com", "3s"},
+		{"http://www.google.com", "3 seconds", "1"},
+		{"http://www.google.com", "3s", "one"},
+	} {
+		options.Dests = nil
+		fs = NewFlagSet("goptions", &options)
+		err = fs.Parse(args)
+		if err == nil {
+			t.Fatalf("Parsing %#v should have failed", args)
+		}
+	}
+}
+
+func TestParse_VerbPositionals(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbs
+		Copy struct {
+			Force  bool     `goptions:"-f"`
+			Source string   `goptions:"pos=1, name='SOURCE', obligatory"`
+			Dests  []string `goptions:"pos=2, name='DEST'"`
+		} `goptions:"copy"`
+	}
+
+	args = []string{"copy", "a", "-f", "b", "c"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(options.Copy.Force &&
+		options.Copy.Source == "a" &&
+		reflect.DeepEqual(options.Copy.Dests, []string{"b", "c"})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+	if pos := fs.Verbs["copy"].Positionals[1].Source.Position; pos != 3 {
+		t.Fatalf("Unexpected position: %d", pos)
+	}
+}
@@ -0,0 +1,38 @@
+package goptions
+
+import (
+	"os"
+)
+
+// A ParseMode determines how a FlagSet treats arguments which are neither
+// flags nor verbs.
+type ParseMode int
+
+const (
+	// InheritMode uses the ParseMode of the parent FlagSet. On a top-level
+	// FlagSet it is equivalent to PosixMode.
+	InheritMode ParseMode = iota
+	// PosixMode stops flag parsing at the first argument which is neither a
+	// flag nor a verb. This argument and all following ones end up in the
+	// Remainder.
+	PosixMode
+	// PermuteMode collects arguments which are neither flags nor verbs into
+	// the Remainder and continues flag parsing after them. If the environment
+	// variable POSIXLY_CORRECT is set, PermuteMode behaves like PosixMode.
+	PermuteMode
+)
+
+// mode returns the ParseMode effectively used by the FlagSet.
+func (fs *FlagSet) mode() ParseMode {
+	m := PosixMode
+	for s := fs; s != nil; s = s.parent {
+		if s.Mode != InheritMode {
+			m = s.Mode
+			break
+		}
+	}
+	if _, ok := os.LookupEnv("POSIXLY_CORRECT"); ok && m == PermuteMode {
+		return PosixMode
+	}
+	return m
+}
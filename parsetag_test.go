@@ -1,8 +1,11 @@
 package goptions
 
 import (
+	"errors"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParseTag_Minimal(t *testing.T) {
@@ -65,3 +68,199 @@ func flagequal(f1, f2 *Flag) bool {
 		f1.Obligatory == f2.Obligatory &&
 		f1.WasSpecified == f2.WasSpecified
 }
+
+func TestParseTag_Negatable(t *testing.T) {
+	var tag string
+	var e error
+	tag = `-f, --force, negatable`
+	f, e := parseStructField(reflect.ValueOf(false), tag)
+	if e != nil {
+		t.Fatalf("Tag parsing failed: %s", e)
+	}
+	if !f.Negatable {
+		t.Fatalf("Expected negatable flag, got %#v", f)
+	}
+
+	tag = `-f, negatable`
+	_, e = parseStructField(reflect.ValueOf(false), tag)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+
+	tag = `--name, negatable`
+	_, e = parseStructField(reflect.ValueOf(string("")), tag)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParseTag_Counter(t *testing.T) {
+	var tag string
+	var e error
+	tag = `-v, decrement='-q'`
+	_, e = parseStructField(reflect.ValueOf(int(0)), tag)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+
+	tag = `-v, count`
+	_, e = parseStructField(reflect.ValueOf(string("")), tag)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestParseVerbTag(t *testing.T) {
+	var tag string
+	var e error
+	var fs *FlagSet
+	tag = `delete, description='Delete an entity', alias='rm,del', hidden`
+	fs = &FlagSet{}
+	e = parseVerbTag(fs, tag)
+	if e != nil {
+		t.Fatalf("Tag parsing failed: %s", e)
+	}
+	if !(fs.Name == "delete" &&
+		fs.Description == "Delete an entity" &&
+		reflect.DeepEqual(fs.Aliases, []string{"rm", "del"}) &&
+		fs.Hidden) {
+		t.Fatalf("Unexpected value: %#v", fs)
+	}
+
+	for _, tag = range []string{``, `--delete`, `name='delete'`, `delete, obligatory`} {
+		e = parseVerbTag(&FlagSet{}, tag)
+		if e == nil {
+			t.Fatalf("Parsing %s should have failed", tag)
+		}
+	}
+}
+
+func TestParseTag_Positional(t *testing.T) {
+	var tag string
+	var e error
+	tag = `pos=2, name='DEST', min=1, max=3, description='Destinations'`
+	f, e := parseStructField(reflect.ValueOf([]string{}), tag)
+	if e != nil {
+		t.Fatalf("Tag parsing failed: %s", e)
+	}
+	if !(f.isPositional() &&
+		f.Name() == "DEST" &&
+		f.Obligatory &&
+		f.optionMeta["min"] == 1 &&
+		f.optionMeta["max"] == 3) {
+		t.Fatalf("Unexpected value: %#v", f)
+	}
+
+	for _, tag = range []string{
+		`-s, pos=1`,
+		`pos=0`,
+		`pos=x`,
+		`name='SOURCE'`,
+		`pos=1, min=1`,
+	} {
+		_, e = parseStructField(reflect.ValueOf(string("")), tag)
+		if e == nil {
+			t.Fatalf("Parsing %s should have failed", tag)
+		}
+	}
+
+	for tag, value := range map[string]interface{}{
+		`pos=2, count`:                 int(0),
+		`pos=1, count, decrement='-q'`: int(0),
+		`pos=1, env='SOURCE'`:          "",
+		`pos=1, mutexgroup='input'`:    "",
+		`pos=1, persistent`:            "",
+		`pos=1, optional='-'`:          "",
+		`negatable, pos=1`:             false,
+	} {
+		_, e = parseStructField(reflect.ValueOf(value), tag)
+		if e == nil || !strings.HasSuffix(e.Error(), "is invalid for positional arguments") {
+			t.Fatalf("Unexpected error for %s: %v", tag, e)
+		}
+	}
+}
+
+func TestParseTag_Map(t *testing.T) {
+	var tag string
+	var e error
+	tag = `-l, --label, kvsep=':', sep=';', duplicates=error`
+	f, e := parseStructField(reflect.ValueOf(map[string]int{}), tag)
+	if e != nil {
+		t.Fatalf("Tag parsing failed: %s", e)
+	}
+	if !(f.IsMap() &&
+		f.IsMulti() &&
+		f.Placeholder() == "KEY:VALUE" &&
+		f.optionMeta["sep"] == ";" &&
+		f.optionMeta["duplicates"] == "error") {
+		t.Fatalf("Unexpected value: %#v", f)
+	}
+
+	_, e = parseStructField(reflect.ValueOf(map[string]int{}), `--label, duplicates=first`)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+	_, e = parseStructField(reflect.ValueOf(map[int]int{}), `--label`)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+	_, e = parseStructField(reflect.ValueOf([]string{}), `--label, kvsep=':'`)
+	if e == nil {
+		t.Fatalf("Parsing should have failed")
+	}
+}
+
+func TestNewFlagSetE(t *testing.T) {
+	var options struct {
+		Name     string        `goptions:"-n, --name"`
+		Other    string        `goptions:"-n, --other"`
+		Color    string        `goptions:"--color, negatable"`
+		Channel  chan int      `goptions:"--channel"`
+		Nameless string        `goptions:"description='No name'"`
+		Debug    bool          `goptions:"-d, --debug, persistent"`
+		Timeout  time.Duration `goptions:"-t, --timeout, bogus"`
+		Count    int           `goptions:"pos=1, count"`
+		Usage    Help          `goptions:"pos=2"`
+		Rest     []string      `goptions:"remainder"`
+		Remainder
+
+		Verbs  `goptions:"pos=3"`
+		Delete struct {
+			Debug bool `goptions:"--debug"`
+			Force bool `goptions:"-f, --force"`
+		} `goptions:"delete"`
+	}
+
+	fs, err := NewFlagSetE("goptions", &options)
+	if fs != nil || err == nil {
+		t.Fatalf("Creating the FlagSet should have failed")
+	}
+	errs, ok := err.(Errors)
+	if !ok {
+		t.Fatalf("Unexpected error type: %#v", err)
+	}
+	expected := []string{
+		"Invalid struct field Color: Option negatable is invalid for type string",
+		"Invalid struct field Channel: Unsupported flag type: chan int",
+		"Invalid struct field Nameless: Flag has neither a short nor a long name",
+		"Invalid struct field Timeout: Unknown option bogus",
+		"Invalid struct field Count: Option count is invalid for positional arguments",
+		"Invalid struct field Usage: Option pos is invalid for type goptions.Help",
+		"Invalid struct field Remainder: Remainder is already defined by Rest",
+		"Invalid struct field Verbs: Option pos is invalid for type goptions.Verbs",
+		"Invalid struct field Other: Flag -n is already defined by Name",
+		"Invalid struct field Delete.Debug: Flag --debug is already defined by Debug",
+	}
+	if len(errs) != len(expected) {
+		t.Fatalf("Unexpected errors: %s", err)
+	}
+	for i, e := range errs {
+		if e.Error() != expected[i] {
+			t.Fatalf("Unexpected error: %s", e)
+		}
+		var fe *FieldError
+		if !errors.As(e, &fe) {
+			t.Fatalf("Unexpected error type: %#v", e)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package goptions
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// isPositional returns true if the flag is a positional argument.
+func (f *Flag) isPositional() bool {
+	_, ok := f.optionMeta["pos"]
+	return ok
+}
+
+// sortPositionals orders the positional arguments of the FlagSet by their
+// position and checks that positions are unique and that only the last
+// positional argument is variadic.
+func (fs *FlagSet) sortPositionals() Errors {
+	var errs Errors
+	sort.SliceStable(fs.Positionals, func(i, j int) bool {
+		return fs.Positionals[i].optionMeta["pos"].(int) < fs.Positionals[j].optionMeta["pos"].(int)
+	})
+	for i, f := range fs.Positionals {
+		field := fs.structValue.Type().Field(f.index).Name
+		if i > 0 && f.optionMeta["pos"] == fs.Positionals[i-1].optionMeta["pos"] {
+			errs = append(errs, &FieldError{Field: field, Err: fmt.Errorf("Multiple positional arguments at position %d", f.optionMeta["pos"])})
+		}
+		if f.IsMulti() && i != len(fs.Positionals)-1 {
+			errs = append(errs, &FieldError{Field: field, Err: fmt.Errorf("Only the last positional argument can be a slice")})
+		}
+	}
+	return errs
+}
+
+// setPositionals assigns the trailing arguments to the positional arguments
+// of the FlagSet in order. A variadic positional argument takes all
+// remaining arguments up to its maximum. positions holds the index of each
+// argument in the original command line. The unassigned arguments and their
+// positions are returned.
+func (fs *FlagSet) setPositionals(args []string, positions []int) ([]string, []int, error) {
+	for _, f := range fs.Positionals {
+		if len(args) == 0 {
+			break
+		}
+		n := 1
+		if f.IsMulti() {
+			n = len(args)
+			if max, ok := f.optionMeta["max"].(int); ok && max < n {
+				n = max
+			}
+			if min, ok := f.optionMeta["min"].(int); ok && n < min {
+				return args, positions, &MissingValueError{ErrorContext{Flag: f, Verbs: fs.verbPath(), Index: -1}, min}
+			}
+		}
+		f.resetDefault()
+		for i, arg := range args[:n] {
+			err := f.setValue(arg)
+			if err != nil {
+				err = fs.recoverError(fs.addContext(f.conversionError(arg, err), positions[i]))
+				if err != nil {
+					return args, positions, err
+				}
+			}
+			fs.addToken(Token{Flag: f, Value: arg, Position: positions[i]})
+		}
+		f.WasSpecified = true
+		f.Source = Source{Kind: SourceArgs, Position: positions[0]}
+		args, positions = args[n:], positions[n:]
+	}
+	return args, positions, nil
+}
+
+// setRemainder converts the arguments to the element type of the remainder
+// and stores them in it. positions holds the index of each argument in the
+// original command line.
+func (f *Flag) setRemainder(args []string, positions []int) error {
+	f.value.Set(reflect.MakeSlice(f.value.Type(), 0, len(args)))
+	for i, arg := range args {
+		err := f.setValue(arg)
+		if err != nil {
+			err = f.flagSet.addContext(f.conversionError(arg, err), positions[i])
+			err = f.flagSet.recoverError(fmt.Errorf("Invalid argument %d (%s): %w", positions[i], arg, err))
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		f.flagSet.addToken(Token{Flag: f, Value: arg, Position: positions[i]})
+	}
+	f.WasSpecified = true
+	f.Source = Source{Kind: SourceArgs, Position: positions[0]}
+	return nil
+}
+
+// isRemainder returns true if the flag catches all excessive arguments.
+func (f *Flag) isRemainder() bool {
+	if _, ok := f.value.Interface().(Remainder); ok {
+		return true
+	}
+	_, ok := f.optionMeta["remainder"]
+	return ok
+}
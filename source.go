@@ -0,0 +1,103 @@
+package goptions
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+)
+
+// SourceKind describes which kind of source a flag's value came from.
+type SourceKind int
+
+const (
+	// The flag has not been set and holds its default value.
+	SourceDefault SourceKind = iota
+	// The value has been given on the command line.
+	SourceArgs
+	// The value has been taken from an environment variable.
+	SourceEnv
+	// The value has been taken from a configuration file.
+	SourceConfig
+)
+
+// A Source describes where the value of a flag came from.
+type Source struct {
+	Kind SourceKind
+	// Name of the environment variable or path of the configuration file.
+	Name string
+	// Line in the configuration file.
+	Line int
+	// Index of the flag in the command line arguments. If a flag has been
+	// specified multiple times, the last occurrence is recorded.
+	Position int
+}
+
+func (s Source) String() string {
+	switch s.Kind {
+	case SourceArgs:
+		return fmt.Sprintf("argument %d", s.Position)
+	case SourceEnv:
+		return fmt.Sprintf("environment $%s", s.Name)
+	case SourceConfig:
+		return fmt.Sprintf("config %s:%d", s.Name, s.Line)
+	}
+	return "default"
+}
+
+// Explain prints a table of the effective values of all flags, positional
+// arguments and the remainder of the FlagSet and its selected verbs together
+// with their sources. Values of flags with the `secret` option are redacted.
+// The remainder is listed by the name of its struct field.
+func (fs *FlagSet) Explain(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 4, 4, 1, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tVALUE\tSOURCE")
+	path := fs.selectedPath()
+	for _, set := range path {
+		for _, f := range set.Flags {
+			// Decrement flags are reported through their counter.
+			if f.isDecrement() {
+				continue
+			}
+			explainFlag(tw, f, f.Name())
+		}
+		for _, f := range set.Positionals {
+			explainFlag(tw, f, f.Name())
+		}
+	}
+	if f := path[len(path)-1].remainderFlag; f != nil {
+		explainFlag(tw, f, f.flagSet.structValue.Type().Field(f.index).Name)
+	}
+	tw.Flush()
+}
+
+// explainFlag prints the row of Explain() for f.
+func explainFlag(w io.Writer, f *Flag, name string) {
+	prefix := strings.Join(append(f.flagSet.verbPath(), ""), " ")
+	value := formatValue(f.value.Interface())
+	if f.IsSecret() {
+		value = "******"
+	}
+	fmt.Fprintf(w, "%s%s\t%s\t%s\n", prefix, name, value, f.Source)
+}
+
+// formatValue renders a flag's value for Explain().
+func formatValue(v interface{}) string {
+	if file, ok := v.(*os.File); ok {
+		if file == nil {
+			return "<nil>"
+		}
+		return file.Name()
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice {
+		parts := make([]string, rv.Len())
+		for i := range parts {
+			parts[i] = formatValue(rv.Index(i).Interface())
+		}
+		return "[" + strings.Join(parts, " ") + "]"
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,86 @@
+package goptions
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSource(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options configOptions
+
+	path, cleanup := writeConfig(t, "config.ini", "name = SomeName\n\n[delete]\nforce = true\n")
+	defer cleanup()
+
+	os.Setenv("GOPTIONS_TIMEOUT", "3s")
+	defer os.Unsetenv("GOPTIONS_TIMEOUT")
+
+	args = []string{"-s", "a", "--config", path, "-s", "b", "delete"}
+	fs = NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "GOPTIONS"
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+
+	expected := map[*Flag]Source{
+		fs.FlagByName("--server"):                {Kind: SourceArgs, Position: 4},
+		fs.FlagByName("--config"):                {Kind: SourceArgs, Position: 2},
+		fs.FlagByName("--timeout"):               {Kind: SourceEnv, Name: "GOPTIONS_TIMEOUT"},
+		fs.FlagByName("--name"):                  {Kind: SourceConfig, Name: path, Line: 1},
+		fs.FlagByName("--color"):                 {Kind: SourceDefault},
+		fs.Verbs["delete"].FlagByName("--force"): {Kind: SourceConfig, Name: path, Line: 4},
+	}
+	for f, source := range expected {
+		if f.Source != source {
+			t.Fatalf("Unexpected source for %s: %#v", f.Name(), f.Source)
+		}
+	}
+}
+
+func TestSource_Explain(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Password string        `goptions:"-p, --password, secret"`
+		Timeout  time.Duration `goptions:"-t, --timeout"`
+		Verbose  int           `goptions:"-v, --verbose, count, decrement='-q'"`
+		Remainder
+
+		Verbs
+		Delete struct {
+			Name   string `goptions:"-n, --name"`
+			Target string `goptions:"pos=1, name='TARGET'"`
+		} `goptions:"delete"`
+	}
+
+	os.Setenv("GOPTIONS_PASSWORD", "hunter2")
+	defer os.Unsetenv("GOPTIONS_PASSWORD")
+
+	options.Timeout = 10 * time.Second
+	args = []string{"-q", "-q", "delete", "-n", "SomeName", "--", "x", "y"}
+	fs = NewFlagSet("goptions", &options)
+	fs.EnvPrefix = "GOPTIONS"
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+
+	buf := &bytes.Buffer{}
+	fs.Explain(buf)
+	expected := "FLAG          VALUE    SOURCE\n" +
+		"--password    ******   environment $GOPTIONS_PASSWORD\n" +
+		"--timeout     10s      default\n" +
+		"--verbose     -2       argument 1\n" +
+		"delete --name SomeName argument 3\n" +
+		"delete TARGET x        argument 6\n" +
+		"Remainder     [y]      argument 7\n"
+	if buf.String() != expected {
+		t.Fatalf("Unexpected output:\n%s", buf.String())
+	}
+}
@@ -1,5 +1,9 @@
 package goptions
 
+import (
+	"reflect"
+)
+
 // Help Defines the common help flag. It is handled separately as it will cause
 // Parse() to return ErrHelpRequest.
 type Help bool
@@ -12,3 +16,20 @@ type Verbs string
 // the containing options struct have a remainder field, only the latter one
 // will be used.
 type Remainder []string
+
+// ConfigFile is the path of a configuration file which provides values for
+// all flags specified neither on the command line nor in the environment.
+// Files ending in `.json` are parsed as JSON, files ending in `.env` as
+// dotenv files and all others as INI files.
+type ConfigFile string
+
+// isSpecialType returns true for the types above, which cannot be used as
+// positional arguments.
+func isSpecialType(t reflect.Type) bool {
+	switch t {
+	case reflect.TypeOf(Help(false)), reflect.TypeOf(Verbs("")),
+		reflect.TypeOf(Remainder{}), reflect.TypeOf(ConfigFile("")):
+		return true
+	}
+	return false
+}
@@ -0,0 +1,107 @@
+package goptions
+
+import (
+	"sort"
+	"strings"
+)
+
+// suggest returns the candidates closest to name. Candidates which differ
+// in more than a third of the name's characters (but at least 1) are not
+// considered.
+func suggest(name string, candidates []string) []string {
+	max := len(name) / 3
+	if max < 1 {
+		max = 1
+	}
+	sort.Strings(candidates)
+	r := []string{}
+	for _, c := range candidates {
+		d := editDistance(name, c)
+		if d > max || (len(r) > 0 && r[len(r)-1] == c) {
+			continue
+		}
+		if d < max {
+			max, r = d, r[0:0]
+		}
+		r = append(r, c)
+	}
+	return r
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	cur := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev, cur = cur, prev
+	}
+	return prev[len(b)]
+}
+
+func minInt(values ...int) int {
+	r := values[0]
+	for _, v := range values[1:] {
+		if v < r {
+			r = v
+		}
+	}
+	return r
+}
+
+// suggestFlags returns the long flags accepted by the FlagSet which are
+// closest to the unknown flag arg.
+func (fs *FlagSet) suggestFlags(arg string) []string {
+	if !isLong(arg) {
+		return nil
+	}
+	name, _, _ := splitLong(arg)
+	candidates := []string{}
+	for set := fs; set != nil; set = set.parent {
+		for _, f := range set.Flags {
+			if len(f.Long) == 0 || (set != fs && !f.Persistent) {
+				continue
+			}
+			candidates = append(candidates, f.Long)
+			if f.Negatable {
+				candidates = append(candidates, "no-"+f.Long)
+			}
+		}
+	}
+	r := suggest(name, candidates)
+	for i := range r {
+		r[i] = "--" + r[i]
+	}
+	return r
+}
+
+// suggestVerbs returns the names of the visible verbs which are closest to
+// name.
+func (fs *FlagSet) suggestVerbs(name string) []string {
+	candidates := []string{}
+	for _, verb := range fs.Verbs {
+		if !verb.Hidden {
+			candidates = append(candidates, verb.Name)
+			candidates = append(candidates, verb.Aliases...)
+		}
+	}
+	return suggest(name, candidates)
+}
+
+// didYouMean formats the suggestions for an error message.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	return ", did you mean " + strings.Join(suggestions, " or ") + "?"
+}
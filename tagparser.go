@@ -11,19 +11,33 @@ const (
 	_LONG_FLAG_REGEXP     = `--[[:word:]-]+`
 	_SHORT_FLAG_REGEXP    = `-[[:alnum:]]`
 	_QUOTED_STRING_REGEXP = `'((?:\\'|[^\\'])+)'`
-	_OPTION_REGEXP        = `([[:word:]-]+)(?:=` + _QUOTED_STRING_REGEXP + `)?`
+	_BARE_VALUE_REGEXP    = `([^,'\s]+)`
+	_OPTION_REGEXP        = `([[:word:]-]+)(?:=(?:` + _QUOTED_STRING_REGEXP + `|` + _BARE_VALUE_REGEXP + `))?`
 )
 
 var (
 	optionRegexp = regexp.MustCompile(`^(` + strings.Join([]string{_SHORT_FLAG_REGEXP, _LONG_FLAG_REGEXP, _OPTION_REGEXP}, "|") + `)(?:,|$)`)
 )
 
+// optionValue returns the (quoted or bare) value of the option matched by
+// optionRegexp at idx.
+func optionValue(tag string, idx []int) (string, bool) {
+	if idx[6] != -1 {
+		return tag[idx[6]:idx[7]], true
+	}
+	if idx[8] != -1 {
+		return tag[idx[8]:idx[9]], true
+	}
+	return "", false
+}
+
 func parseStructField(fieldValue reflect.Value, tag string) (*Flag, error) {
 	f := &Flag{
 		value:        fieldValue,
-		DefaultValue: fieldValue.Interface(),
+		DefaultValue: copyValue(fieldValue).Interface(),
 		optionMeta:   make(map[string]interface{}),
 	}
+	options := make(map[string]bool)
 	for {
 		tag = strings.TrimSpace(tag)
 		if len(tag) == 0 {
@@ -47,15 +61,15 @@ func parseStructField(fieldValue reflect.Value, tag string) (*Flag, error) {
 			f.Short = option[1:]
 		} else {
 			option := tag[idx[4]:idx[5]]
-			value := ""
-			if idx[6] != -1 {
-				value = tag[idx[6]:idx[7]]
-			}
+			value, _ := optionValue(tag, idx)
 			optionmap := optionMapForType(fieldValue.Type())
 			opf, ok := optionmap[option]
-			if !ok {
+			if !ok && isKnownOption(option) {
+				return nil, fmt.Errorf("Option %s is invalid for type %s", option, fieldValue.Type())
+			} else if !ok {
 				return nil, fmt.Errorf("Unknown option %s", option)
 			}
+			options[option] = true
 			err := opf(f, option, value)
 			if err != nil {
 				return nil, fmt.Errorf("Option %s invalid: %s", option, err)
@@ -64,5 +78,107 @@ func parseStructField(fieldValue reflect.Value, tag string) (*Flag, error) {
 		// Keep remainder
 		tag = tag[idx[1]:]
 	}
+	if _, ok := f.optionMeta["remainder"]; ok && (f.Short != "" || f.Long != "" || f.isPositional() || !f.IsMulti()) {
+		return nil, fmt.Errorf("Option remainder is only valid for slices without flag names")
+	}
+	if f.IsMap() && fieldValue.Type().Key().Kind() != reflect.String {
+		return nil, fmt.Errorf("Map keys have to be strings")
+	}
+	if f.isPositional() && (f.Short != "" || f.Long != "") {
+		return nil, fmt.Errorf("Positional arguments cannot have flag names")
+	}
+	// Options which only affect flags would be silently ignored.
+	for _, option := range []string{"count", "decrement", "env", "mutexgroup", "persistent", "optional", "negatable"} {
+		if options[option] && f.isPositional() {
+			return nil, fmt.Errorf("Option %s is invalid for positional arguments", option)
+		}
+	}
+	for _, option := range []string{"name", "min", "max"} {
+		if _, ok := f.optionMeta[option]; ok && !f.isPositional() {
+			return nil, fmt.Errorf("Option %s needs option pos", option)
+		}
+		if _, ok := f.optionMeta[option]; ok && option != "name" && !f.IsMulti() {
+			return nil, fmt.Errorf("Option %s is only valid for slices", option)
+		}
+	}
+	if f.Negatable && f.Long == "" {
+		return nil, fmt.Errorf("Option negatable needs a long flag name")
+	}
+	if f.IsOptional() && (f.isBool() || f.isCounter()) {
+		return nil, fmt.Errorf("Option optional is invalid for flags without a value")
+	}
+	if _, ok := f.optionMeta["decrement"]; ok && !f.isCounter() {
+		return nil, fmt.Errorf("Option decrement needs option count")
+	}
 	return f, nil
 }
+
+// copyValue returns a copy of slices and maps so later changes to v are
+// not reflected in the copy. Other values are returned unchanged.
+func copyValue(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(c, v)
+		return c
+	case v.Kind() == reflect.Map && !v.IsNil():
+		c := reflect.MakeMap(v.Type())
+		for _, key := range v.MapKeys() {
+			c.SetMapIndex(key, v.MapIndex(key))
+		}
+		return c
+	}
+	return v
+}
+
+// decrementFlag creates the flag which decrements the counter flag f. Its
+// names are taken from the decrement option of f.
+func decrementFlag(f *Flag) (*Flag, error) {
+	dec, err := parseStructField(f.value, f.optionMeta["decrement"].(string))
+	if err != nil {
+		return nil, fmt.Errorf("Option decrement invalid: %s", err)
+	}
+	dec.optionMeta["count"] = -1
+	dec.Description = "Decrement " + f.Name()
+	dec.DefaultValue = nil
+	dec.counter = f
+	return dec, nil
+}
+
+// parseVerbTag parses the tag of a verb field. The tag starts with the name
+// of the verb followed by verb options.
+func parseVerbTag(fs *FlagSet, tag string) error {
+	for {
+		tag = strings.TrimSpace(tag)
+		if len(tag) == 0 {
+			break
+		}
+		idx := optionRegexp.FindStringSubmatchIndex(tag)
+		if idx == nil || idx[4] == -1 {
+			return fmt.Errorf("Could not find a valid verb definition at the beginning of \"%s\"", tag)
+		}
+		option := tag[idx[4]:idx[5]]
+		value, hasValue := optionValue(tag, idx)
+		if len(fs.Name) == 0 {
+			if hasValue {
+				return fmt.Errorf("Verb name expected at the beginning of \"%s\"", tag)
+			}
+			fs.Name = option
+		} else {
+			opf, ok := verbOptionMap[option]
+			if !ok {
+				return fmt.Errorf("Unknown option %s", option)
+			}
+			err := opf(fs, option, value)
+			if err != nil {
+				return fmt.Errorf("Option %s invalid: %s", option, err)
+			}
+		}
+		// Keep remainder
+		tag = tag[idx[1]:]
+	}
+	if len(fs.Name) == 0 {
+		return fmt.Errorf("Verb has no name")
+	}
+	return nil
+}
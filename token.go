@@ -0,0 +1,38 @@
+package goptions
+
+import (
+	"sort"
+)
+
+// A Token is a single element of the command line as recognized by the last
+// call to Parse().
+type Token struct {
+	// Flag the token has been assigned to. This is the positional argument
+	// or the remainder for arguments which are no flags. nil for verbs.
+	Flag *Flag
+	// Verb selected by the token. nil for flags.
+	Verb *FlagSet
+	// Name of the flag as given on the command line (e.g. `-v` inside of
+	// `-vvf` or `--no-color`) or the name of the verb. Empty for positional
+	// arguments and the remainder.
+	Name string
+	// Value assigned to the flag.
+	Value string
+	// Index of the argument in the arguments passed to Parse().
+	Position int
+}
+
+// Tokens returns the elements of the command line recognized by the last call
+// to Parse() in the order they have been given.
+func (fs *FlagSet) Tokens() []Token {
+	r := append([]Token{}, fs.root().tokens...)
+	sort.SliceStable(r, func(i, j int) bool {
+		return r[i].Position < r[j].Position
+	})
+	return r
+}
+
+func (fs *FlagSet) addToken(t Token) {
+	root := fs.root()
+	root.tokens = append(root.tokens, t)
+}
@@ -0,0 +1,70 @@
+package goptions
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse_DoesNotModifyArgs(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose int    `goptions:"-v, count"`
+		Force   bool   `goptions:"-f"`
+		Name    string `goptions:"-n"`
+	}
+
+	args = []string{"-vvf", "-vnName"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !reflect.DeepEqual(args, []string{"-vvf", "-vnName"}) {
+		t.Fatalf("Arguments have been modified: %#v", args)
+	}
+	if !(options.Verbose == 3 && options.Force && options.Name == "Name") {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}
+
+func TestFlagSet_Tokens(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Verbose bool   `goptions:"-v, --verbose"`
+		Name    string `goptions:"-n, --name"`
+		Color   bool   `goptions:"--color, negatable"`
+
+		Verbs
+		Copy struct {
+			Force  bool   `goptions:"-f"`
+			Source string `goptions:"pos=1"`
+			Remainder
+		} `goptions:"copy"`
+	}
+
+	args = []string{"-vnName", "--no-color", "copy", "-f", "src", "rest", "-"}
+	fs = NewFlagSet("goptions", &options)
+	fs.Mode = PermuteMode
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	copyFlags := fs.Verbs["copy"]
+	expected := []Token{
+		{Flag: fs.Flags[0], Name: "-v", Position: 0},
+		{Flag: fs.Flags[1], Name: "-n", Value: "Name", Position: 0},
+		{Flag: fs.Flags[2], Name: "--no-color", Value: "false", Position: 1},
+		{Verb: copyFlags, Name: "copy", Position: 2},
+		{Flag: copyFlags.Flags[0], Name: "-f", Position: 3},
+		{Flag: copyFlags.Positionals[0], Value: "src", Position: 4},
+		{Flag: copyFlags.remainderFlag, Value: "rest", Position: 5},
+		{Flag: copyFlags.remainderFlag, Value: "-", Position: 6},
+	}
+	if !reflect.DeepEqual(fs.Tokens(), expected) {
+		t.Fatalf("Unexpected tokens: %#v", fs.Tokens())
+	}
+}
@@ -1,12 +1,14 @@
 package goptions
 
 import (
+	"bytes"
 	"fmt"
 	"net"
 	"net/url"
 	"os"
 	"reflect"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -22,6 +24,7 @@ var (
 		reflect.TypeOf(new(int64)).Elem():         int64ValueParser,
 		reflect.TypeOf(new(int32)).Elem():         int32ValueParser,
 		reflect.TypeOf(new(Help)).Elem():          helpValueParser,
+		reflect.TypeOf(new(ConfigFile)).Elem():    configFileValueParser,
 		reflect.TypeOf(new(*os.File)).Elem():      fileValueParser,
 		reflect.TypeOf(new(*net.TCPAddr)).Elem():  tcpAddrValueParser,
 		reflect.TypeOf(new(*url.URL)).Elem():      urlValueParser,
@@ -48,39 +51,197 @@ func (f *Flag) setValue(s string) (err error) {
 			return
 		}
 	}()
+	if step, ok := f.optionMeta["count"].(int); ok {
+		f.value.SetInt(f.value.Int() + int64(step))
+		return nil
+	}
 	if f.value.Type().Implements(reflect.TypeOf(new(Marshaler)).Elem()) {
 		return parseMarshalValue(f.value, s)
 	}
-	vtype := f.value.Type()
-	newval := reflect.New(vtype).Elem()
-	if f.value.Kind() == reflect.Slice {
-		vtype = f.value.Type().Elem()
-		if vtype.Implements(reflect.TypeOf(new(Marshaler)).Elem()) {
-			newval = reflect.New(vtype).Elem()
-			err := parseMarshalValue(newval, s)
-			f.value.Set(reflect.Append(f.value, newval))
+	switch f.value.Kind() {
+	case reflect.Slice:
+		parts, err := f.splitValue(s)
+		if err != nil {
 			return err
 		}
+		for _, part := range parts {
+			val, err := f.parseElement(f.value.Type().Elem(), part)
+			if val.IsValid() {
+				f.value.Set(reflect.Append(f.value, val))
+			}
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		parts, err := f.splitValue(s)
+		if err != nil {
+			return err
+		}
+		for _, part := range parts {
+			err := f.setMapValue(part)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	}
-	if parser, ok := parserMap[vtype]; ok {
+	val, err := f.parseElement(f.value.Type(), s)
+	if err != nil {
+		return err
+	}
+	f.value.Set(val)
+	return nil
+}
+
+// parseElement converts s to a value of type t using a Marshaler or the
+// parserMap. An invalid value is returned if s could not be converted.
+func (f *Flag) parseElement(t reflect.Type, s string) (reflect.Value, error) {
+	if t.Implements(reflect.TypeOf(new(Marshaler)).Elem()) {
+		val := reflect.New(t).Elem()
+		return val, parseMarshalValue(val, s)
+	}
+	if parser, ok := parserMap[t]; ok {
 		val, err := parser(f, s)
 		if err != nil {
-			return err
+			return reflect.Value{}, err
+		}
+		return val, nil
+	}
+	return reflect.Value{}, fmt.Errorf("Unsupported flag type: %s", f.value.Type())
+}
+
+// setMapValue adds the `key=value` pair in s to the map.
+func (f *Flag) setMapValue(s string) error {
+	kvsep := f.keyValueSeparator()
+	idx := strings.Index(s, kvsep)
+	if idx == -1 {
+		return fmt.Errorf("Invalid pair \"%s\", expected KEY%sVALUE", s, kvsep)
+	}
+	return f.setMapIndex(s[:idx], s[idx+len(kvsep):])
+}
+
+// setMapIndex converts value and stores it at key in the map.
+func (f *Flag) setMapIndex(key, value string) error {
+	if f.value.IsNil() {
+		f.value.Set(reflect.MakeMap(f.value.Type()))
+	}
+	k := reflect.ValueOf(key).Convert(f.value.Type().Key())
+	if f.optionMeta["duplicates"] == "error" && f.value.MapIndex(k).IsValid() {
+		return fmt.Errorf("Duplicate key %s", key)
+	}
+	val, err := f.parseElement(f.value.Type().Elem(), value)
+	if err != nil {
+		return err
+	}
+	f.value.SetMapIndex(k, val)
+	return nil
+}
+
+// splitValue splits s at the flag's `sep` option. s is returned as the only
+// element if the flag has none.
+func (f *Flag) splitValue(s string) ([]string, error) {
+	if sep, ok := f.optionMeta["sep"].(string); ok {
+		return splitList(s, sep)
+	}
+	return []string{s}, nil
+}
+
+// splitList splits s at every occurrence of sep which is neither quoted
+// nor escaped. An element can be enclosed in single or double quotes. A
+// backslash escapes sep, a quote or a backslash.
+func splitList(s, sep string) ([]string, error) {
+	r := make([]string, 0)
+	buf := new(bytes.Buffer)
+	quote, start := byte(0), true
+	for i := 0; i < len(s); {
+		escaped := s[i] == '\\' && i+1 < len(s)
+		switch {
+		case quote != 0 && escaped && (s[i+1] == quote || s[i+1] == '\\'):
+			buf.WriteByte(s[i+1])
+			i += 2
+		case quote != 0 && s[i] == quote:
+			quote = 0
+			i++
+			if i < len(s) && !strings.HasPrefix(s[i:], sep) {
+				return nil, fmt.Errorf("Unexpected character after quote in \"%s\"", s)
+			}
+		case quote != 0:
+			buf.WriteByte(s[i])
+			i++
+		case start && (s[i] == '"' || s[i] == '\''):
+			quote, start = s[i], false
+			i++
+		case strings.HasPrefix(s[i:], sep):
+			r = append(r, buf.String())
+			buf.Reset()
+			start = true
+			i += len(sep)
+		case escaped && strings.HasPrefix(s[i+1:], sep):
+			buf.WriteString(sep)
+			start = false
+			i += 1 + len(sep)
+		case escaped && (s[i+1] == '\\' || s[i+1] == '"' || s[i+1] == '\''):
+			buf.WriteByte(s[i+1])
+			start = false
+			i += 2
+		default:
+			buf.WriteByte(s[i])
+			start = false
+			i++
 		}
-		if f.value.Kind() == reflect.Slice {
-			f.value.Set(reflect.Append(f.value, val))
-		} else {
-			f.value.Set(val)
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("Unterminated quote in \"%s\"", s)
+	}
+	return append(r, buf.String()), nil
+}
+
+func (f *Flag) keyValueSeparator() string {
+	if kvsep, ok := f.optionMeta["kvsep"].(string); ok {
+		return kvsep
+	}
+	return "="
+}
+
+// setExternalValue sets the flag's value from a string which has not been
+// given on the command line. For slices and maps, the string is split at
+// the flag's `sep` option or, if it has none, at sep. Counters are set to
+// the given number.
+func (f *Flag) setExternalValue(s, sep string) error {
+	if f.isCounter() {
+		val, err := parserMap[f.value.Type()](f, s)
+		if err != nil {
+			return err
 		}
+		f.value.Set(val)
 		return nil
-	} else {
-		return fmt.Errorf("Unsupported flag type: %s", f.value.Type())
 	}
-	panic("Invalid execution path")
+	if _, ok := f.optionMeta["sep"]; ok || len(sep) == 0 || !f.IsMulti() {
+		return f.setValue(s)
+	}
+	parts, err := splitList(s, sep)
+	if err != nil {
+		return err
+	}
+	for _, part := range parts {
+		err := f.setValue(part)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func boolValueParser(f *Flag, val string) (reflect.Value, error) {
-	return reflect.ValueOf(true), nil
+	switch strings.ToLower(val) {
+	case "", "true", "yes", "1":
+		return reflect.ValueOf(true), nil
+	case "false", "no", "0":
+		return reflect.ValueOf(false), nil
+	}
+	return reflect.Value{}, fmt.Errorf("Invalid boolean value \"%s\"", val)
 }
 
 func stringValueParser(f *Flag, val string) (reflect.Value, error) {
@@ -161,3 +322,7 @@ func timeValueParser(f *Flag, val string) (reflect.Value, error) {
 func helpValueParser(f *Flag, val string) (reflect.Value, error) {
 	return reflect.Value{}, ErrHelpRequest
 }
+
+func configFileValueParser(f *Flag, val string) (reflect.Value, error) {
+	return reflect.ValueOf(ConfigFile(val)), nil
+}
@@ -4,6 +4,7 @@ import (
 	"net"
 	"net/url"
 	"os"
+	"reflect"
 	"testing"
 	"time"
 )
@@ -110,3 +111,51 @@ func TestParse_Time(t *testing.T) {
 		t.Fatalf("Unexpected value: %#v", got)
 	}
 }
+
+func TestSplitList(t *testing.T) {
+	for s, expected := range map[string][]string{
+		`a,b,c`:        {"a", "b", "c"},
+		`a,,c`:         {"a", "", "c"},
+		`"a,b",c`:      {"a,b", "c"},
+		`'a,"b"',c`:    {`a,"b"`, "c"},
+		`a\,b,c`:       {"a,b", "c"},
+		`C:\dir,d\\,e`: {`C:\dir`, `d\`, "e"},
+		`it's,"\"x\""`: {"it's", `"x"`},
+	} {
+		parts, err := splitList(s, ",")
+		if err != nil {
+			t.Fatalf("Splitting %s failed: %s", s, err)
+		}
+		if !reflect.DeepEqual(parts, expected) {
+			t.Fatalf("Unexpected value for %s: %#v", s, parts)
+		}
+	}
+
+	for _, s := range []string{`"a,b`, `"a"b,c`} {
+		_, err := splitList(s, ",")
+		if err == nil {
+			t.Fatalf("Splitting %s should have failed", s)
+		}
+	}
+}
+
+func TestParse_SliceSep(t *testing.T) {
+	var args []string
+	var err error
+	var fs *FlagSet
+	var options struct {
+		Servers []string `goptions:"-s, --server, sep=','"`
+		Ports   []int    `goptions:"-p, --port, sep=':'"`
+	}
+
+	args = []string{"-s", "a,b", "-s", `"c,d"`, "--port=80:443"}
+	fs = NewFlagSet("goptions", &options)
+	err = fs.Parse(args)
+	if err != nil {
+		t.Fatalf("Parsing failed: %s", err)
+	}
+	if !(reflect.DeepEqual(options.Servers, []string{"a", "b", "c,d"}) &&
+		reflect.DeepEqual(options.Ports, []int{80, 443})) {
+		t.Fatalf("Unexpected value: %#v", options)
+	}
+}